@@ -0,0 +1,362 @@
+package vara
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/dig"
+)
+
+// bootstrap tracks the provider constructors of an application and the dependencies
+// between them. It can build the providers ahead of the controllers, running the
+// constructors of providers that don't depend on each other concurrently.
+type bootstrap struct {
+	ctx   *buildContext
+	mu    *sync.Mutex // the lock of the application's container, see [injector]
+	calls []*ctorCall
+}
+
+func newBootstrap(ctx *buildContext, mu *sync.Mutex) *bootstrap {
+	return &bootstrap{
+		ctx: ctx,
+		mu:  mu,
+	}
+}
+
+// ctorCall is a provider constructor registered with the bootstrap.
+type ctorCall struct {
+	once    sync.Once
+	scope   scope
+	ctor    reflect.Value
+	deps    []reflect.Type
+	outputs []reflect.Type
+	results []reflect.Value
+
+	// dependencies are the constructors building the values the constructor depends on.
+	dependencies []*ctorCall
+}
+
+// call calls the constructor with args once, and returns its results on every call.
+// Concurrent calls wait for the first call to complete. calling reports if the call is
+// made by the container, holding its lock.
+//
+// A [Lifecycle] passed to the constructor is replaced by one that attributes the
+// hooks appended to it to the constructor, and [Lazy] dependencies are bound to the injector.
+func (c *ctorCall) call(i *injector, args []reflect.Value, calling bool) []reflect.Value {
+	c.once.Do(func() {
+		for j, arg := range args {
+			args[j] = c.bindLifecycle(arg)
+		}
+		c.results = newLazyInjector(i, calling).call(c.ctor, args)
+	})
+	return c.results
+}
+
+// bindLifecycle returns v with any [Lifecycle] in it, including those
+// in parameter structs embedding [In], bound to the constructor.
+func (c *ctorCall) bindLifecycle(v reflect.Value) reflect.Value {
+	switch {
+	case (v.Type() == lifecycleType) && (!v.IsNil()):
+		return reflect.ValueOf(v.Interface().(*Lifecycle).withOwner(c))
+
+	case (v.Kind() == reflect.Struct) && (dig.IsIn(v.Type())):
+		bound := reflect.New(v.Type()).Elem()
+		bound.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if bound.Field(i).CanSet() {
+				bound.Field(i).Set(c.bindLifecycle(v.Field(i)))
+			}
+		}
+		return bound
+
+	default:
+		return v
+	}
+}
+
+// err returns the error returned by the constructor, if any.
+func (c *ctorCall) err() error {
+	n := len(c.results)
+	if (n == 0) || (c.ctor.Type().Out(n-1) != errorType) || (c.results[n-1].IsNil()) {
+		return nil
+	}
+	return c.results[n-1].Interface().(error)
+}
+
+// resolveArgs resolves the constructor's parameters, and the injector of
+// its scope, from its scope. The caller must hold the container's lock.
+func (c *ctorCall) resolveArgs() (*injector, []reflect.Value, error) {
+	var (
+		i    *injector
+		args []reflect.Value
+	)
+
+	resolve := withInjector(c.ctor.Type(), nil, func(in *injector, params []reflect.Value) []reflect.Value {
+		i, args = in, params
+		return nil
+	})
+
+	return i, args, c.scope.Invoke(resolve.Interface())
+}
+
+// wrap returns a constructor with the same results as the provider's constructor, that
+// returns the results of the bootstrap's call to it. It has the same parameters, and also
+// depends on the injector of the scope. It reports false if the constructor is not a function.
+//
+// The wrapped constructor also returns an error if the provider's constructor doesn't, as it
+// fails without calling the constructor once building the application is aborted.
+func (b *bootstrap) wrap(s scope, spec *providerSpec) (constructor, []dig.ProvideOption, bool) {
+	fn := reflect.ValueOf(spec.ctor)
+	if fn.Kind() != reflect.Func {
+		return nil, nil, false
+	}
+
+	c := &ctorCall{
+		scope:   s,
+		ctor:    fn,
+		deps:    getDependencyTypes(fn.Type()),
+		outputs: getOutputTypes(spec),
+	}
+	b.calls = append(b.calls, c)
+
+	out := getResultTypes(fn.Type())
+	returnsErr := (len(out) > 0) && (out[len(out)-1] == errorType)
+	if !returnsErr {
+		out = append(out, errorType)
+	}
+
+	wrapped := withInjector(fn.Type(), out, func(i *injector, args []reflect.Value) []reflect.Value {
+		err := b.ctx.Err()
+		if err != nil {
+			results := make([]reflect.Value, len(out))
+			for j, t := range out[:len(out)-1] {
+				results[j] = reflect.Zero(t)
+			}
+			results[len(out)-1] = reflect.ValueOf(fmt.Errorf("bootstrap aborted: %w", err))
+			return results
+		}
+
+		results := c.call(i, args, true)
+		if !returnsErr {
+			results = append(results, reflect.Zero(errorType))
+		}
+		return results
+	})
+	opts := []dig.ProvideOption{
+		// report the wrapped constructor's location in errors
+		dig.LocationForPC(fn.Pointer()),
+	}
+
+	return wrapped.Interface(), opts, true
+}
+
+// link resolves the dependencies of every registered constructor. It must be
+// called once every module's providers have been registered.
+func (b *bootstrap) link() {
+	builtBy := map[reflect.Type][]*ctorCall{}
+	for _, c := range b.calls {
+		for _, t := range c.outputs {
+			builtBy[t] = append(builtBy[t], c)
+		}
+	}
+
+	for _, c := range b.calls {
+		c.dependencies = nil
+		for _, t := range c.deps {
+			for _, dep := range builtBy[t] {
+				if dep != c {
+					c.dependencies = append(c.dependencies, dep)
+				}
+			}
+		}
+	}
+}
+
+// run calls every registered constructor. Constructors are called in waves, each wave
+// made of the constructors whose dependencies were built by the previous waves, and the
+// constructors in a wave are called concurrently.
+//
+// run returns early if the bootstrap context is done or a constructor fails.
+func (b *bootstrap) run() error {
+	var (
+		pending  = b.calls
+		isCalled = map[*ctorCall]bool{}
+	)
+
+	isReady := func(c *ctorCall) bool {
+		for _, dep := range c.dependencies {
+			if !isCalled[dep] {
+				return false
+			}
+		}
+		return true
+	}
+
+	for len(pending) > 0 {
+		var ready, rest []*ctorCall
+
+		for _, c := range pending {
+			if isReady(c) {
+				ready = append(ready, c)
+			} else {
+				rest = append(rest, c)
+			}
+		}
+
+		if len(ready) == 0 {
+			// the remaining constructors depend on each other through types the
+			// dependency graph can't tell apart, e.g. differently named values.
+			// dig builds them in order as their arguments are resolved.
+			ready, rest = rest, nil
+		}
+
+		err := b.runWave(ready)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range ready {
+			isCalled[c] = true
+		}
+		pending = rest
+	}
+
+	return nil
+}
+
+// runWave resolves the arguments of the constructors and calls them concurrently. The
+// caller must hold the container's lock, which is released while the constructors run.
+//
+// runWave returns once building is aborted without waiting for the running constructors,
+// which are left to return in the background. The container's lock is only taken again
+// once the constructors resolving lazy dependencies release it.
+func (b *bootstrap) runWave(calls []*ctorCall) error {
+	var (
+		wg   sync.WaitGroup
+		done = make(chan struct{})
+		errs = make(chan error, len(calls))
+	)
+
+	for _, c := range calls {
+		err := b.ctx.Err()
+		if err != nil {
+			return fmt.Errorf("bootstrap aborted: %w", err)
+		}
+
+		i, args, err := c.resolveArgs()
+		if err != nil {
+			return err
+		}
+
+		wg.Add(1)
+		go func(c *ctorCall) {
+			defer wg.Done()
+
+			c.call(i, args, false)
+			err := c.err()
+			if err != nil {
+				errs <- fmt.Errorf("error building provider (%s): %w", c.ctor.Type(), err)
+			}
+		}(c)
+	}
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	// the constructors take the container's lock to resolve lazy dependencies
+	b.mu.Unlock()
+	defer b.mu.Lock()
+
+	select {
+	case <-done:
+		close(errs)
+		return <-errs
+
+	case <-b.ctx.Done():
+		return fmt.Errorf("bootstrap aborted: %w", b.ctx.Err())
+	}
+}
+
+// buildContext is the context injected into constructors. While the application is built, it
+// carries the deadline of the bootstrap context, and it is cancelled if building is aborted.
+// Since constructors may also be called once the application is built, e.g. for [Lazy]
+// dependencies, it is not cancelled otherwise, and has no deadline once the application is built.
+type buildContext struct {
+	context.Context // cancelled with the cause of the abort
+
+	bootstrap context.Context
+	built     atomic.Bool
+}
+
+// newBuildContext returns a build context carrying the values of the bootstrap context,
+// and the function aborting building the application.
+func newBuildContext(ctx context.Context) (*buildContext, context.CancelCauseFunc) {
+	inner, abort := context.WithCancelCause(context.WithoutCancel(ctx))
+	return &buildContext{Context: inner, bootstrap: ctx}, abort
+}
+
+func (c *buildContext) Deadline() (time.Time, bool) {
+	if c.built.Load() {
+		return time.Time{}, false
+	}
+	return c.bootstrap.Deadline()
+}
+
+// Err returns context.DeadlineExceeded if building was aborted as the bootstrap deadline was exceeded.
+func (c *buildContext) Err() error {
+	err := c.Context.Err()
+	if (err != nil) && (errors.Is(context.Cause(c.Context), context.DeadlineExceeded)) {
+		return context.DeadlineExceeded
+	}
+	return err
+}
+
+// getDependencyTypes returns the types of the values that a constructor depends on.
+// Parameter structs embedding [In] are flattened into their fields' types.
+func getDependencyTypes(fnType reflect.Type) []reflect.Type {
+	var types []reflect.Type
+	for i := 0; i < fnType.NumIn(); i++ {
+		types = append(types, getParamTypes(fnType.In(i))...)
+	}
+	return types
+}
+
+// getParamTypes returns the types of the values requested by a constructor parameter.
+func getParamTypes(t reflect.Type) []reflect.Type {
+	if (t.Kind() != reflect.Struct) || (!dig.IsIn(t)) {
+		return []reflect.Type{t}
+	}
+
+	var types []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		switch {
+		case ((f.Anonymous) && (f.Type == inType)) || (f.PkgPath != ""):
+			continue
+		case (f.Tag.Get("group") != "") && (f.Type.Kind() == reflect.Slice):
+			types = append(types, f.Type.Elem())
+		default:
+			types = append(types, getParamTypes(f.Type)...)
+		}
+	}
+	return types
+}
+
+// getOutputTypes returns the types of the values that a constructor registers.
+func getOutputTypes(ctor constructor) []reflect.Type {
+	var types []reflect.Type
+
+	keys, _ := getExportKeys(ctor)
+	for _, key := range keys {
+		types = append(types, key.valueType())
+	}
+
+	return types
+}
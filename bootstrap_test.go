@@ -0,0 +1,129 @@
+package vara
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type variadicService struct {
+	opts []string
+}
+
+func newVariadicService(opts ...string) *variadicService {
+	return &variadicService{opts: opts}
+}
+
+// variadicModule provides a service built by a variadic constructor to its controller.
+type variadicModule struct {
+	got **variadicService
+}
+
+func (m *variadicModule) Config() *ModuleConfig {
+	return &ModuleConfig{
+		ProviderConstructors: []ProviderConstructor{newVariadicService},
+		ControllerConstructors: []ControllerConstructor{
+			func(s *variadicService) *testController {
+				*m.got = s
+				return &testController{}
+			},
+		},
+	}
+}
+
+func TestVariadicProvider(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"lazy build", nil},
+		{"concurrent build", []Option{WithConcurrentBuild()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *variadicService
+
+			_, err := New(&variadicModule{got: &got}, tt.opts...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			if (got == nil) || (len(got.opts) != 0) {
+				t.Errorf("service = %+v, want a service without options", got)
+			}
+		})
+	}
+}
+
+func TestBootstrapTimeout(t *testing.T) {
+	type (
+		blocked struct{}
+		after   struct{}
+	)
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"lazy build", nil},
+		{"concurrent build", []Option{WithConcurrentBuild()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				release     = make(chan struct{})
+				hasDeadline atomic.Bool
+				afterCalled atomic.Bool
+			)
+			defer close(release)
+
+			root := &ctorModule{
+				providers: []ProviderConstructor{
+					// ignores the context's cancellation
+					func(ctx context.Context) *blocked {
+						_, ok := ctx.Deadline()
+						hasDeadline.Store(ok)
+						<-release
+						return &blocked{}
+					},
+					func(*blocked) *after {
+						afterCalled.Store(true)
+						return &after{}
+					},
+				},
+				controllers: []ControllerConstructor{
+					func(*after) *testController { return &testController{} },
+				},
+			}
+
+			done := make(chan error, 1)
+			go func() {
+				_, err := New(root, append(tt.opts, WithBootstrapTimeout(50*time.Millisecond))...)
+				done <- err
+			}()
+
+			select {
+			case err := <-done:
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("New() error = %v, want %v", err, context.DeadlineExceeded)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("New() did not return once the bootstrap timeout was exceeded")
+			}
+
+			if !hasDeadline.Load() {
+				t.Error("the injected context has no deadline")
+			}
+
+			release <- struct{}{}
+			time.Sleep(10 * time.Millisecond)
+			if afterCalled.Load() {
+				t.Error("a constructor was called after building was aborted")
+			}
+		})
+	}
+}
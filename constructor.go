@@ -1,5 +1,10 @@
 package vara
 
+import (
+	"fmt"
+	"reflect"
+)
+
 // constructor is a function that takes any number of dependencies
 // as its parameters and build then returns an arbitrary number of values of
 // one or more type and may optionally return an error to indicate that it failed to build the value(s).
@@ -7,3 +12,54 @@ package vara
 // Any arguments that the constructor has are treated as its dependencies. The dependencies are instantiated
 // in an unspecified order along with any dependencies that they might have.
 type constructor any
+
+// callConstructor calls the constructor with its dependencies resolved from the scope, and
+// returns its results without the trailing error. The caller must hold the container's lock. Unlike providing the constructor to the
+// scope, this doesn't register its results, so they can't be collected by other constructors.
+func callConstructor(s scope, ctor constructor) ([]reflect.Value, error) {
+	fn := reflect.ValueOf(ctor)
+	if fn.Kind() != reflect.Func {
+		return nil, fmt.Errorf("constructor must be a function, got %T", ctor)
+	}
+
+	var (
+		fnType  = fn.Type()
+		results []reflect.Value
+	)
+
+	invoker := withInjector(fnType, nil, func(i *injector, args []reflect.Value) []reflect.Value {
+		results = newLazyInjector(i, true).call(fn, args)
+		return nil
+	})
+
+	err := s.Invoke(invoker.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	if n := len(results); (n > 0) && (fnType.Out(n-1) == errorType) {
+		if err, _ := results[n-1].Interface().(error); err != nil {
+			return nil, err
+		}
+		results = results[:n-1]
+	}
+
+	return results, nil
+}
+
+// callFunc calls fn with args. The last argument of a variadic fn is the slice of its variadic arguments.
+func callFunc(fn reflect.Value, args []reflect.Value) []reflect.Value {
+	if fn.Type().IsVariadic() {
+		return fn.CallSlice(args)
+	}
+	return fn.Call(args)
+}
+
+// getResultTypes returns the types of the results of a function of the type.
+func getResultTypes(fnType reflect.Type) []reflect.Type {
+	out := make([]reflect.Type, fnType.NumOut())
+	for i := range out {
+		out[i] = fnType.Out(i)
+	}
+	return out
+}
@@ -0,0 +1,192 @@
+package vara
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Context is the context of a request handled by a route. It describes the route and holds
+// values shared by the guards, interceptors and handler handling the request.
+//
+// It is attached to the request's context and retrieved with [FromRequest]:
+//
+//	var userKey = vara.NewKey[*User]("user")
+//
+//	func (g *AuthGuard) Allow(gCtx vara.GuardContext) (bool, error) {
+//		user, err := g.auth.Validate(gCtx.Http.R.Header.Get("Authorization"))
+//		if err != nil {
+//			return false, err
+//		}
+//
+//		userKey.Set(gCtx.Context(), user)
+//		return true, nil
+//	}
+//
+//	func (c *UserController) getUser(w http.ResponseWriter, r *http.Request) {
+//		user, _ := userKey.Get(vara.FromRequest(r))
+//		...
+//	}
+type Context struct {
+	// Route describes the route handling the request.
+	Route RouteInfo
+
+	// Params are the values of the wildcards in the route's path, by name.
+	Params map[string]string
+
+	mu         sync.RWMutex
+	values     map[any]any
+	rejectedBy Guard
+}
+
+// contextKey is the request context key of the [Context].
+type contextKey struct{}
+
+// withContext returns a shallow copy of r carrying a new [Context] for the route.
+func withContext(r *http.Request, route RouteInfo, params []string) *http.Request {
+	c := &Context{
+		Route:  route,
+		Params: make(map[string]string, len(params)),
+		values: make(map[any]any),
+	}
+	for _, name := range params {
+		c.Params[name] = r.PathValue(name)
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), contextKey{}, c))
+}
+
+// FromRequest returns the [Context] of a request handled by a route, or nil if r is not.
+func FromRequest(r *http.Request) *Context {
+	return FromContext(r.Context())
+}
+
+// FromContext returns the [Context] carried by ctx, or nil if ctx carries none.
+func FromContext(ctx context.Context) *Context {
+	c, _ := ctx.Value(contextKey{}).(*Context)
+	return c
+}
+
+// Param returns the value of the wildcard of the route's path with the given name.
+func (c *Context) Param(name string) string {
+	if c == nil {
+		return ""
+	}
+	return c.Params[name]
+}
+
+// RouteMetadata returns the metadata of the route handling the request.
+func (c *Context) RouteMetadata() Metadata {
+	if c == nil {
+		return nil
+	}
+	return c.Route.RouteConfig.Metadata
+}
+
+// ControllerMetadata returns the metadata of the controller of the route handling the request.
+func (c *Context) ControllerMetadata() Metadata {
+	if c == nil {
+		return nil
+	}
+	return c.Route.ControllerConfig.Metadata
+}
+
+// Set stores a value under the key. Prefer the typed accessors of a [Key].
+func (c *Context) Set(key, value any) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.values[key] = value
+}
+
+// Get returns the value stored under the key. Prefer the typed accessors of a [Key].
+func (c *Context) Get(key any) (any, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	v, ok := c.values[key]
+	return v, ok
+}
+
+// Key is a typed key of values stored in a [Context]. Keys are compared by identity,
+// so values stored under a key can only be retrieved with the same key.
+type Key[T any] struct {
+	name string
+}
+
+// NewKey returns a new key of values of type T. The name is only used for debugging.
+func NewKey[T any](name string) *Key[T] {
+	return &Key[T]{name: name}
+}
+
+func (k *Key[T]) String() string { return k.name }
+
+// Set stores the value under the key in c.
+func (k *Key[T]) Set(c *Context, value T) {
+	c.Set(k, value)
+}
+
+// Get returns the value stored under the key in c, if any.
+func (k *Key[T]) Get(c *Context) (T, bool) {
+	v, ok := c.Get(k)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+
+	value, ok := v.(T)
+	return value, ok
+}
+
+// RejectingGuard returns the guard that rejected the request, if any.
+//
+// It is meant to be called by an [Interceptor] once the handler it wraps returns.
+func RejectingGuard(r *http.Request) (Guard, bool) {
+	c := FromRequest(r)
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.rejectedBy, (c.rejectedBy != nil)
+}
+
+// setRejectedBy records the guard that rejected the request.
+func (c *Context) setRejectedBy(g Guard) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rejectedBy = g
+}
+
+// getPathParams returns the names of the wildcards in a path pattern,
+// e.g. ["id", "rest"] for "/users/{id}/files/{rest...}".
+func getPathParams(path string) []string {
+	var params []string
+	for _, segment := range strings.Split(path, pathSeparator) {
+		if (!strings.HasPrefix(segment, "{")) || (!strings.HasSuffix(segment, "}")) {
+			continue
+		}
+
+		name := strings.TrimSuffix(strings.Trim(segment, "{}"), "...")
+		if (name != "") && (name != "$") {
+			params = append(params, name)
+		}
+	}
+	return params
+}
@@ -3,6 +3,7 @@ package vara
 import (
 	"cmp"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"strings"
 
@@ -24,9 +25,23 @@ type ControllerConstructor constructor
 // controller is a wrapper for managing an instance of a Controller.
 type controller struct {
 	Controller
-	module *module
-	routes []*route
-	guards []*guard
+	cors         *corsPolicy
+	module       *module
+	routes       []*route
+	guards       []*guard
+	moduleGuards []*guard
+}
+
+// routeInput is used for injecting the dependencies of the controller's route handlers.
+type routeInput struct {
+	dig.In
+	Server       *httpServer
+	Logger       *slog.Logger
+	GlobalCORS   *globalCORS
+	GlobalGuards *globalGuards
+	Renderer     RejectionRenderer `name:"vara.rejection_renderer" optional:"true"`
+	Observers    []RouteObserver   `group:"route.observers"`
+	Interceptors []Interceptor     `group:"interceptors"`
 }
 
 const (
@@ -44,11 +59,22 @@ func newController(c Controller, m *module) (*controller, error) {
 		Controller: c,
 	}
 
-	err := ctrl._registerGuards()
+	moduleGuards, err := m._getGuards()
+	if err != nil {
+		return nil, err
+	}
+	ctrl.moduleGuards = moduleGuards
+
+	err = ctrl._registerGuards()
 	if err != nil {
 		return nil, fmt.Errorf("error registering guards: %w", err)
 	}
 
+	err = ctrl._registerCORS()
+	if err != nil {
+		return nil, fmt.Errorf("error registering CORS config: %w", err)
+	}
+
 	err = ctrl._registerRoutes()
 	if err != nil {
 		return nil, err
@@ -60,50 +86,163 @@ func newController(c Controller, m *module) (*controller, error) {
 // getPath constructs the full path for a route
 // by combining the controller's root pattern with the route's pattern.
 func (c *controller) getPath(r route) string {
+	return strings.TrimSpace(
+		fmt.Sprintf("%s %s", r.Method, c.getRoutePath(r)),
+	)
+}
+
+// getRoutePath combines the controller's root pattern with the route's pattern.
+func (c *controller) getRoutePath(r route) string {
 	root := cmp.Or(c.Config().Pattern, defaultPath)
 	path := strings.TrimPrefix(r.Pattern, pathSeparator)
 
-	return strings.TrimSpace(
-		fmt.Sprintf(
-			"%s %s", r.Method, strings.Join([]string{root, path}, pathSeparator),
-		),
-	)
+	return strings.TrimSuffix(root, pathSeparator) + pathSeparator + path
 }
 
-// getGuards retrieves the list of guards for a given route,
-// including both controller-scoped guards and route-scoped guards.
+// getRouteInfo describes the route for interceptors and the request [Context].
+func (c *controller) getRouteInfo(r route) RouteInfo {
+	return RouteInfo{
+		Method:           r.Method,
+		Path:             c.getRoutePath(r),
+		RouteConfig:      *r.RouteConfig,
+		ControllerConfig: *c.Config(),
+		ModuleConfig:     *c.module.Config(),
+		Module:           getModuleName(c.module.Module),
+	}
+}
+
+// getGuards retrieves the list of guards for a given route, in the order they run: the
+// module-scoped guards, unless the route is public, followed by the controller-scoped
+// and route-scoped guards. Global guards run before them and are added per request.
 func (c *controller) getGuards(r route) []*guard {
-	return append(c.guards, r.guards...)
+	var guards []*guard
+	if !c.isPublic(r) {
+		guards = append(guards, c.moduleGuards...)
+	}
+	guards = append(guards, c.guards...)
+	guards = append(guards, r.guards...)
+	return guards
 }
 
-func (c *controller) getHandler(r route) http.Handler {
+// isPublic reports whether the route, its controller or its module is marked [Public].
+func (c *controller) isPublic(r route) bool {
+	isPublic, _ := Reflect[bool](getRouteMetadata(c.getRouteInfo(r), metadataPublic))
+	return isPublic
+}
+
+// getHandler returns the route's handler, running the route's guards
+// before the handler and wrapped by the interceptors, in order.
+//
+// The observers are notified of the request and of every interceptor and guard handling it.
+func (c *controller) getHandler(r route, input routeInput) http.Handler {
 	var (
-		guards  = c.getGuards(r)
-		handler = r.Handler
+		guards       = c.getGuards(r)
+		isPublic     = c.isPublic(r)
+		handler      = r.Handler
+		info         = c.getRouteInfo(r)
+		params       = getPathParams(info.Path)
+		observers    = routeObservers(input.Observers)
+		interceptors = input.Interceptors
 	)
 
-	return http.HandlerFunc(
+	var next http.Handler = http.HandlerFunc(
 		func(w http.ResponseWriter, req *http.Request) {
+			chain := guards
+			if global := input.GlobalGuards.get(); (len(global) > 0) && (!isPublic) {
+				chain = append(append([]*guard{}, global...), guards...)
+			}
+
 			gCtx := newGuardCtx(*c, r, w, req)
-			allowed, err := c.runGuards(gCtx, guards)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
+			allowed, err := c.runGuards(gCtx, chain, info, observers)
+			if (!allowed) || (err != nil) {
+				c.reject(w, req, err, input)
 				return
 			}
-			if !allowed {
-				http.Error(w, "Forbidden", http.StatusForbidden)
+
+			handler.ServeHTTP(w, req)
+		},
+	)
+
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		next = c.intercept(interceptors[i], info, next, observers)
+	}
+
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			req = withContext(req, info, params)
+			if len(observers) == 0 {
+				next.ServeHTTP(w, req)
 				return
 			}
 
-			handler.ServeHTTP(w, req)
+			req, done := observers.observeRequest(req, info)
+			rec := NewStatusRecorder(w)
+			next.ServeHTTP(rec, req)
+			done(rec.Status())
 		},
 	)
 }
 
-func (c *controller) runGuards(gCtx GuardContext, guards []*guard) (bool, error) {
+// reject writes the response of a request rejected by a guard with err.
+func (c *controller) reject(w http.ResponseWriter, r *http.Request, err error, input routeInput) {
+	rejection := getRejection(err)
+	if rejection.Status >= http.StatusInternalServerError {
+		input.Logger.ErrorContext(r.Context(), "guard failed", "path", r.URL.Path, "error", err)
+	}
+
+	for key, values := range rejection.Header {
+		w.Header()[key] = values
+	}
+
+	renderer := input.Renderer
+	if renderer == nil {
+		renderer = textRejectionRenderer{}
+	}
+	renderer.RenderRejection(w, r, rejection)
+}
+
+// intercept wraps next with the interceptor, notifying the observers when the interceptor handles a request.
+func (c *controller) intercept(i Interceptor, info RouteInfo, next http.Handler, observers routeObservers) http.Handler {
+	h := i.Intercept(info, next)
+	if len(observers) == 0 {
+		return h
+	}
+
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			req, done := observers.observeInterceptor(req, info, i)
+			defer done()
+			h.ServeHTTP(w, req)
+		},
+	)
+}
+
+// checkRoute checks the route with the guards applied to it that implement [RouteChecker].
+func (c *controller) checkRoute(r route, global *globalGuards) error {
+	info := c.getRouteInfo(r)
+
+	err := checkRoute(c.getGuards(r), info)
+	if err != nil {
+		return err
+	}
+
+	if c.isPublic(r) {
+		return nil
+	}
+	return global.guardRoute(info)
+}
+
+func (c *controller) runGuards(gCtx GuardContext, guards []*guard, info RouteInfo, observers routeObservers) (bool, error) {
+	req := gCtx.Http.R
 	for _, guard := range guards {
+		var done func(bool, error)
+		gCtx.Http.R, done = observers.observeGuard(req, info, guard.Guard)
+
 		allowed, err := guard.Allow(gCtx)
+		done(allowed, err)
+
 		if (!allowed) || (err != nil) {
+			FromRequest(req).setRejectedBy(guard.Guard)
 			return false, err
 		}
 	}
@@ -112,7 +251,12 @@ func (c *controller) runGuards(gCtx GuardContext, guards []*guard) (bool, error)
 
 func (c *controller) _registerRoutes() error {
 	return c.module.scope.Invoke(
-		func(server *httpServer) error {
+		func(input routeInput) error {
+			cors := c.cors
+			if cors == nil {
+				cors = input.GlobalCORS.policy
+			}
+
 			for _, rCfg := range c.Config().RouteConfigs {
 				// create route from config
 				r, err := newRoute(rCfg, c)
@@ -121,47 +265,47 @@ func (c *controller) _registerRoutes() error {
 				}
 				c.routes = append(c.routes, r)
 
+				err = c.checkRoute(*r, input.GlobalGuards)
+				if err != nil {
+					return err
+				}
+
 				// register route handler for it's path
-				server.mux.Handle(c.getPath(*r), c.getHandler(*r))
+				input.Server.handleRoute(r.Method, c.getRoutePath(*r), c.getHandler(*r, input), cors)
 			}
 			return nil
 		},
 	)
 }
 
-func (c *controller) _registerGuards() error {
-	var (
-		cCfg = c.Config()
-		opts = []dig.ProvideOption{
-			dig.As(new(Guard)),
-			dig.Group(groupGuards.String()),
-		}
-	)
+// _registerCORS resolves the CORS policy of the controller's routes: its own, or its module's.
+func (c *controller) _registerCORS() error {
+	policy, err := newCORSPolicy(c.module.scope, c.Config().CORS)
+	if err != nil {
+		return fmt.Errorf("error resolving controller CORS config (%T): %w", c.Controller, err)
+	}
 
-	for _, grd := range cCfg.Guards {
-		err := c.module.scope.Provide(func() Guard { return grd }, opts...)
+	if policy == nil {
+		policy, err = c.module._getCORS()
 		if err != nil {
-			return fmt.Errorf("error providing controller guard (%T): %w", grd, err)
+			return err
 		}
 	}
 
-	for _, grdCtor := range cCfg.GuardConstructors {
-		err := c.module.scope.Provide(grdCtor, opts...)
-		if err != nil {
-			return fmt.Errorf("error providing controller guard (%T): %w", grdCtor, err)
-		}
+	c.cors = policy
+	return nil
+}
+
+// _registerGuards resolves the guards listed by the controller's config. Guard constructors
+// are called with dependencies from the module's scope without registering their guards in
+// it, so the controller's guards are exactly those it lists.
+func (c *controller) _registerGuards() error {
+	cCfg := c.Config()
+	guards, err := resolveGuards(c.module.scope, cCfg.Guards, cCfg.GuardConstructors)
+	if err != nil {
+		return fmt.Errorf("error resolving controller guards (%T): %w", c.Controller, err)
 	}
 
-	return c.module.scope.Invoke(
-		func(input guardGroupInput) error {
-			for _, grd := range input.Guards {
-				g, err := newGuard(grd)
-				if err != nil {
-					return err
-				}
-				c.guards = append(c.guards, g)
-			}
-			return nil
-		},
-	)
+	c.guards = guards
+	return nil
 }
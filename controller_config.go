@@ -7,8 +7,8 @@ type ControllerConfig struct {
 	// within the controller.
 	Pattern string
 
-	// Metadata holds arbitrary metadata associated with the controller.
-	Metadata any
+	// Metadata holds metadata associated with the controller, read with a [Reflector].
+	Metadata Metadata
 
 	// RouteConfigs lists all routes managed by the controller.
 	RouteConfigs []*RouteConfig
@@ -19,4 +19,8 @@ type ControllerConfig struct {
 	// GuardConstructors provides constructors for creating guard instances that
 	// requires dependency injection.
 	GuardConstructors []GuardConstructor
+
+	// CORS configures Cross-Origin Resource Sharing for the controller's routes,
+	// overriding the config of its module and application.
+	CORS *CORSConfig
 }
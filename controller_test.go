@@ -0,0 +1,40 @@
+package vara
+
+import (
+	"net/http"
+	"testing"
+)
+
+// patternController is a controller with a root pattern and no routes.
+type patternController struct {
+	pattern string
+}
+
+func (c patternController) Config() *ControllerConfig {
+	return &ControllerConfig{Pattern: c.pattern}
+}
+
+func TestControllerRoutePath(t *testing.T) {
+	tests := []struct {
+		root    string
+		pattern string
+		want    string
+	}{
+		{"", "/healthz", "GET /healthz"},
+		{"/", "/healthz", "GET /healthz"},
+		{"/", "/", "GET /"},
+		{"/users", "/", "GET /users/"},
+		{"/users", "/{id}", "GET /users/{id}"},
+		{"/users/", "/{id}", "GET /users/{id}"},
+		{"/users", "{id}", "GET /users/{id}"},
+	}
+
+	for _, tt := range tests {
+		c := &controller{Controller: patternController{pattern: tt.root}}
+		r := route{RouteConfig: &RouteConfig{Method: http.MethodGet, Pattern: tt.pattern}}
+
+		if got := c.getPath(r); got != tt.want {
+			t.Errorf("getPath(%q, %q) = %q, want %q", tt.root, tt.pattern, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,380 @@
+package vara
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS headers
+const (
+	headerOrigin                        = "Origin"
+	headerVary                          = "Vary"
+	headerAllow                         = "Allow"
+	headerAccessControlRequestMethod    = "Access-Control-Request-Method"
+	headerAccessControlRequestHeaders   = "Access-Control-Request-Headers"
+	headerAccessControlAllowOrigin      = "Access-Control-Allow-Origin"
+	headerAccessControlAllowMethods     = "Access-Control-Allow-Methods"
+	headerAccessControlAllowHeaders     = "Access-Control-Allow-Headers"
+	headerAccessControlAllowCredentials = "Access-Control-Allow-Credentials"
+	headerAccessControlExposeHeaders    = "Access-Control-Expose-Headers"
+	headerAccessControlMaxAge           = "Access-Control-Max-Age"
+)
+
+// wildcard allows any origin or header in a [CORSConfig].
+const wildcard = "*"
+
+var (
+	// ErrInvalidCORSConfig indicates a CORS config is invalid, e.g. allows credentials from any origin
+	ErrInvalidCORSConfig = errors.New("invalid CORS config")
+
+	// ErrConflictingCORS indicates a module is imported by modules applying different CORS configs
+	ErrConflictingCORS = errors.New("conflicting CORS configs")
+)
+
+// defaultCORSMethods are the methods allowed by preflights of routes matching any method.
+var defaultCORSMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// OriginFunc reports if requests from the origin are allowed.
+type OriginFunc func(r *http.Request, origin string) bool
+
+// OriginFuncConstructor is a function that takes any number of dependencies as its
+// parameters and returns an [OriginFunc], and may optionally return an error to
+// indicate that it failed to build the value.
+type OriginFuncConstructor constructor
+
+// CORSConfig configures Cross-Origin Resource Sharing for the routes of an application, set with
+// [WithCORS], of a module and the modules it imports, or of a controller. The nearest config of a
+// route applies: its controller's, then its module's, then the application's.
+//
+// Preflight OPTIONS requests to the routes are answered automatically, without running their
+// guards, and the responses of the routes carry the CORS headers of allowed origins.
+type CORSConfig struct {
+	// AllowedOrigins lists the origins requests are allowed from: exact origins such as
+	// "https://app.example.com", origins with wildcard subdomains such as
+	// "https://*.example.com", or "*" to allow any origin.
+	AllowedOrigins []string
+
+	// AllowedOriginPatterns lists regular expressions matching the origins requests are allowed
+	// from. Patterns are anchored to match whole origins, so "https://.*\.example\.com" doesn't
+	// match "https://example.com.evil.com".
+	AllowedOriginPatterns []*regexp.Regexp
+
+	// AllowOriginFunc reports if requests from an origin are allowed, besides the allowed origins.
+	AllowOriginFunc OriginFunc
+
+	// AllowOriginFuncConstructor provides the AllowOriginFunc when it requires dependency injection.
+	AllowOriginFuncConstructor OriginFuncConstructor
+
+	// AllowedMethods lists the methods allowed by preflights, the methods of the routes by default.
+	AllowedMethods []string
+
+	// AllowedHeaders lists the request headers allowed by preflights, or "*" to allow any
+	// header. Any header requested by preflights is allowed by default.
+	AllowedHeaders []string
+
+	// ExposedHeaders lists the response headers scripts of allowed origins can read.
+	ExposedHeaders []string
+
+	// AllowCredentials allows requests to include credentials, such as cookies. It can't be
+	// combined with allowing any origin, which would let any site make credentialed requests.
+	AllowCredentials bool
+
+	// MaxAge is how long the results of preflights may be cached.
+	MaxAge time.Duration
+
+	// Disabled disables CORS for the routes, overriding the config of their module or application.
+	Disabled bool
+}
+
+// corsPolicy is a resolved [CORSConfig].
+type corsPolicy struct {
+	disabled    bool
+	anyOrigin   bool
+	origins     []string
+	wildcards   [][2]string
+	patterns    []*regexp.Regexp
+	originFunc  OriginFunc
+	methods     []string
+	anyHeader   bool
+	headers     []string
+	exposed     string
+	credentials bool
+	maxAge      string
+}
+
+// newCORSPolicy resolves the config, building its origin func with dependencies from the scope.
+// It returns nil if the config is nil.
+func newCORSPolicy(s scope, cfg *CORSConfig) (*corsPolicy, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	if cfg.Disabled {
+		return &corsPolicy{disabled: true}, nil
+	}
+
+	p := &corsPolicy{
+		originFunc:  cfg.AllowOriginFunc,
+		exposed:     strings.Join(cfg.ExposedHeaders, ", "),
+		credentials: cfg.AllowCredentials,
+		anyHeader:   (len(cfg.AllowedHeaders) == 0),
+	}
+
+	if cfg.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.ToLower(origin)
+		switch prefix, suffix, isWildcard := strings.Cut(origin, wildcard); {
+		case origin == wildcard:
+			p.anyOrigin = true
+		case isWildcard:
+			p.wildcards = append(p.wildcards, [2]string{prefix, suffix})
+		default:
+			p.origins = append(p.origins, origin)
+		}
+	}
+
+	if p.anyOrigin && p.credentials {
+		return nil, fmt.Errorf("%w: credentials can't be allowed from any origin, list the allowed origins instead", ErrInvalidCORSConfig)
+	}
+
+	for _, pattern := range cfg.AllowedOriginPatterns {
+		anchored, err := regexp.Compile("^(?:" + pattern.String() + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("%w: origin pattern %q: %w", ErrInvalidCORSConfig, pattern, err)
+		}
+		p.patterns = append(p.patterns, anchored)
+	}
+
+	for _, method := range cfg.AllowedMethods {
+		p.methods = append(p.methods, strings.ToUpper(method))
+	}
+
+	for _, header := range cfg.AllowedHeaders {
+		if header == wildcard {
+			p.anyHeader = true
+		}
+		p.headers = append(p.headers, http.CanonicalHeaderKey(header))
+	}
+
+	if cfg.AllowOriginFuncConstructor != nil {
+		results, err := callConstructor(s, cfg.AllowOriginFuncConstructor)
+		if err != nil {
+			return nil, fmt.Errorf("error building origin func (%T): %w", cfg.AllowOriginFuncConstructor, err)
+		}
+		if (len(results) != 1) || (results[0].Type() != reflect.TypeOf(OriginFunc(nil))) {
+			return nil, fmt.Errorf("origin func constructor (%T) must return a single vara.OriginFunc", cfg.AllowOriginFuncConstructor)
+		}
+		p.originFunc = results[0].Interface().(OriginFunc)
+	}
+
+	return p, nil
+}
+
+// enabled reports if the policy applies CORS to its routes.
+func (p *corsPolicy) enabled() bool {
+	return (p != nil) && (!p.disabled)
+}
+
+// allowsOrigin reports if requests from the origin are allowed.
+func (p *corsPolicy) allowsOrigin(r *http.Request, origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+
+	lower := strings.ToLower(origin)
+	if slices.Contains(p.origins, lower) {
+		return true
+	}
+
+	for _, w := range p.wildcards {
+		prefix, suffix := w[0], w[1]
+		if (len(lower) > (len(prefix) + len(suffix))) && strings.HasPrefix(lower, prefix) && strings.HasSuffix(lower, suffix) {
+			// the wildcard only matches subdomains
+			if sub := lower[len(prefix):(len(lower) - len(suffix))]; !strings.ContainsAny(sub, "/:") {
+				return true
+			}
+		}
+	}
+
+	for _, pattern := range p.patterns {
+		if pattern.MatchString(origin) {
+			return true
+		}
+	}
+
+	return (p.originFunc != nil) && p.originFunc(r, origin)
+}
+
+// allowsHeaders reports if the requested headers are allowed.
+func (p *corsPolicy) allowsHeaders(requested []string) bool {
+	if p.anyHeader {
+		return true
+	}
+	for _, header := range requested {
+		if !slices.Contains(p.headers, http.CanonicalHeaderKey(header)) {
+			return false
+		}
+	}
+	return true
+}
+
+// setOriginHeaders sets the headers allowing the origin to read the response, and reports if it is allowed.
+func (p *corsPolicy) setOriginHeaders(w http.ResponseWriter, r *http.Request) bool {
+	h := w.Header()
+	origin := r.Header.Get(headerOrigin)
+
+	// the response varies by origin, unless any origin is allowed
+	if !p.anyOrigin {
+		h.Add(headerVary, headerOrigin)
+	}
+
+	if (origin == "") || (!p.allowsOrigin(r, origin)) {
+		return false
+	}
+
+	if p.anyOrigin {
+		h.Set(headerAccessControlAllowOrigin, wildcard)
+	} else {
+		h.Set(headerAccessControlAllowOrigin, origin)
+	}
+	if p.credentials {
+		h.Set(headerAccessControlAllowCredentials, "true")
+	}
+	return true
+}
+
+// handle wraps the route's handler, setting the CORS headers of its responses.
+func (p *corsPolicy) handle(next http.Handler) http.Handler {
+	if !p.enabled() {
+		return next
+	}
+
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if p.setOriginHeaders(w, r) && (p.exposed != "") {
+				w.Header().Set(headerAccessControlExposeHeaders, p.exposed)
+			}
+			next.ServeHTTP(w, r)
+		},
+	)
+}
+
+// preflight answers the preflight requests of the routes registered at a path.
+type preflight struct {
+	pattern  string
+	policy   *corsPolicy
+	methods  []string
+	fallback http.Handler
+}
+
+// addRoute adds the method of a route registered at the path, and the handler of routes matching
+// any method, which handles OPTIONS requests that are not preflights.
+func (pf *preflight) addRoute(method string, h http.Handler) {
+	methods := []string{method}
+	if method == "" {
+		pf.fallback = h
+		methods = defaultCORSMethods
+	}
+
+	for _, m := range methods {
+		if !slices.Contains(pf.methods, m) {
+			pf.methods = append(pf.methods, m)
+		}
+	}
+}
+
+func (pf *preflight) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		h       = w.Header()
+		p       = pf.policy
+		origin  = r.Header.Get(headerOrigin)
+		method  = r.Header.Get(headerAccessControlRequestMethod)
+		methods = pf.methods
+	)
+
+	if (origin == "") || (method == "") {
+		if pf.fallback != nil {
+			pf.fallback.ServeHTTP(w, r)
+			return
+		}
+		h.Set(headerAllow, strings.Join(append([]string{http.MethodOptions}, methods...), ", "))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	h.Add(headerVary, headerAccessControlRequestMethod)
+	h.Add(headerVary, headerAccessControlRequestHeaders)
+	if len(p.methods) > 0 {
+		methods = p.methods
+	}
+
+	var requested []string
+	for _, header := range strings.Split(r.Header.Get(headerAccessControlRequestHeaders), ",") {
+		if header = strings.TrimSpace(header); header != "" {
+			requested = append(requested, header)
+		}
+	}
+
+	// disallowed preflights are answered without CORS headers, so browsers block the request
+	if !p.setOriginHeaders(w, r) || !slices.Contains(methods, method) || !p.allowsHeaders(requested) {
+		h.Del(headerAccessControlAllowOrigin)
+		h.Del(headerAccessControlAllowCredentials)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	h.Set(headerAccessControlAllowMethods, strings.Join(methods, ", "))
+	if len(requested) > 0 {
+		h.Set(headerAccessControlAllowHeaders, strings.Join(requested, ", "))
+	}
+	if p.maxAge != "" {
+		h.Set(headerAccessControlMaxAge, p.maxAge)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getPreflightKey returns the path with its wildcards unnamed, so paths
+// matching the same requests share their preflight.
+func getPreflightKey(path string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(path, "{")
+		end := strings.Index(path, "}")
+		if (start < 0) || (end < start) {
+			b.WriteString(path)
+			return b.String()
+		}
+
+		b.WriteString(path[:start])
+		switch name := path[(start + 1):end]; {
+		case name == "$":
+			b.WriteString("{$}")
+		case strings.HasSuffix(name, "..."):
+			b.WriteString("{...}")
+		default:
+			b.WriteString("{}")
+		}
+		path = path[(end + 1):]
+	}
+}
+
+// globalCORS holds the CORS policy of the application's routes.
+type globalCORS struct {
+	policy *corsPolicy
+}
@@ -0,0 +1,178 @@
+package vara
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestCORSOriginPatternsMatchWholeOrigins(t *testing.T) {
+	p, err := newCORSPolicy(nil, &CORSConfig{
+		AllowedOriginPatterns: []*regexp.Regexp{regexp.MustCompile(`https://pr-\d+\.example\.com`)},
+	})
+	if err != nil {
+		t.Fatalf("newCORSPolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://pr-12.example.com", true},
+		{"https://pr-12.example.com.evil.com", false},
+		{"http://evil.com/https://pr-12.example.com", false},
+	}
+
+	for _, tt := range tests {
+		got := p.allowsOrigin(httptest.NewRequest("GET", "/", nil), tt.origin)
+		if got != tt.want {
+			t.Errorf("allowsOrigin(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestCORSCredentialsFromAnyOrigin(t *testing.T) {
+	_, err := newCORSPolicy(nil, &CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+	if !errors.Is(err, ErrInvalidCORSConfig) {
+		t.Errorf("newCORSPolicy() error = %v, want %v", err, ErrInvalidCORSConfig)
+	}
+}
+
+// preflightAllows reports if the preflight of a GET request to the path from the origin is allowed.
+func preflightAllows(t *testing.T, app *App, path, origin string) bool {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodOptions, path, nil)
+	r.Header.Set(headerOrigin, origin)
+	r.Header.Set(headerAccessControlRequestMethod, http.MethodGet)
+
+	rec := httptest.NewRecorder()
+	app.httpServer.mux.ServeHTTP(rec, r)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("OPTIONS %s: status = %d, want %d", path, rec.Code, http.StatusNoContent)
+	}
+	return rec.Header().Get(headerAccessControlAllowOrigin) == origin
+}
+
+func corsFrom(origin string) *CORSConfig {
+	return &CORSConfig{AllowedOrigins: []string{origin}}
+}
+
+func TestSharedModuleCORS(t *testing.T) {
+	const (
+		rootOrigin   = "https://root.example.com"
+		sharedOrigin = "https://shared.example.com"
+		aOrigin      = "https://a.example.com"
+	)
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		root    *CORSConfig
+		shared  *CORSConfig
+		a       *CORSConfig
+		want    string // the origin allowed by the shared module's routes
+		wantErr bool
+	}{
+		{"inherited from the root", nil, corsFrom(rootOrigin), nil, nil, rootOrigin, false},
+		{"application config", []Option{WithCORS(*corsFrom(rootOrigin))}, nil, nil, nil, rootOrigin, false},
+		{"own config", nil, corsFrom(rootOrigin), corsFrom(sharedOrigin), corsFrom(aOrigin), sharedOrigin, false},
+		{"conflicting importers", nil, corsFrom(rootOrigin), nil, corsFrom(aOrigin), "", true},
+		{"importer without config", nil, nil, nil, corsFrom(aOrigin), "", true},
+	}
+
+	for _, tt := range tests {
+		for _, order := range [][]string{{"a", "b"}, {"b", "a"}} {
+			t.Run(tt.name+"/"+order[0]+" imported first", func(t *testing.T) {
+				shared := &testModule{
+					cors:        tt.shared,
+					controllers: []*testController{{pattern: "/shared", routes: []*RouteConfig{testRoute("/")}}},
+				}
+
+				importers := map[string]*testModule{
+					"a": {imports: []Module{shared}, cors: tt.a},
+					"b": {imports: []Module{shared}},
+				}
+
+				root := &testModule{cors: tt.root}
+				for _, name := range order {
+					root.imports = append(root.imports, importers[name])
+				}
+
+				app, err := New(root, tt.opts...)
+				if tt.wantErr {
+					if !errors.Is(err, ErrConflictingCORS) {
+						t.Errorf("New() error = %v, want %v", err, ErrConflictingCORS)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("New() error = %v", err)
+				}
+
+				for _, origin := range []string{rootOrigin, sharedOrigin, aOrigin} {
+					if got := preflightAllows(t, app, "/shared/", origin); got != (origin == tt.want) {
+						t.Errorf("preflight from %s allowed: %v, want %v", origin, got, !got)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestControllerCORS(t *testing.T) {
+	const (
+		moduleOrigin     = "https://module.example.com"
+		controllerOrigin = "https://controller.example.com"
+	)
+
+	root := &testModule{
+		cors: corsFrom(moduleOrigin),
+		controllers: []*testController{
+			{pattern: "/inherited", routes: []*RouteConfig{testRoute("/")}},
+			{pattern: "/overridden", cors: corsFrom(controllerOrigin), routes: []*RouteConfig{testRoute("/")}},
+			{pattern: "/disabled", cors: &CORSConfig{Disabled: true}, routes: []*RouteConfig{testRoute("/")}},
+		},
+	}
+
+	app, err := New(root)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want string // the origin allowed by the route
+	}{
+		{"/inherited/", moduleOrigin},
+		{"/overridden/", controllerOrigin},
+	}
+
+	for _, tt := range tests {
+		for _, origin := range []string{moduleOrigin, controllerOrigin} {
+			if got := preflightAllows(t, app, tt.path, origin); got != (origin == tt.want) {
+				t.Errorf("preflight of %s from %s allowed: %v, want %v", tt.path, origin, got, !got)
+			}
+
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			r.Header.Set(headerOrigin, origin)
+			rec := httptest.NewRecorder()
+			app.httpServer.mux.ServeHTTP(rec, r)
+			if got := rec.Header().Get(headerAccessControlAllowOrigin) == origin; got != (origin == tt.want) {
+				t.Errorf("GET %s from %s allowed: %v, want %v", tt.path, origin, got, !got)
+			}
+		}
+	}
+
+	// routes with CORS disabled don't answer preflights
+	r := httptest.NewRequest(http.MethodOptions, "/disabled/", nil)
+	r.Header.Set(headerOrigin, moduleOrigin)
+	r.Header.Set(headerAccessControlRequestMethod, http.MethodGet)
+	rec := httptest.NewRecorder()
+	app.httpServer.mux.ServeHTTP(rec, r)
+	if rec.Header().Get(headerAccessControlAllowOrigin) != "" {
+		t.Errorf("OPTIONS /disabled/: %s = %q, want none", headerAccessControlAllowOrigin, rec.Header().Get(headerAccessControlAllowOrigin))
+	}
+}
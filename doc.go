@@ -83,6 +83,90 @@
 //   - No constructor needed
 //   - Faster initialization
 //
+// # Named and Grouped Providers
+//
+// A provider constructor can be annotated with [Provide] to register several implementations
+// of the same type. [Name] registers a value under a name, [Group] collects values into a slice
+// and [As] registers a value as one or more interfaces instead of its concrete type:
+//
+//	ProviderConstructors: []vara.ProviderConstructor{
+//		vara.Provide(newStripeGateway, vara.Name("stripe"), vara.As(new(PaymentGateway))),
+//		vara.Provide(newPaypalGateway, vara.Group("gateways"), vara.As(new(PaymentGateway))),
+//	}
+//
+// Named and grouped values are requested through a struct embedding [In]:
+//
+//	type PaymentParams struct {
+//		vara.In
+//
+//		Stripe   PaymentGateway   `name:"stripe"`
+//		Gateways []PaymentGateway `group:"gateways"`
+//	}
+//
+// Annotated providers are exported like any other provider by listing them, with the
+// same options, in ExportConstructors.
+//
+// [Global] registers a provider's values in the application's root scope instead, making them
+// available to every module. This lets a module discover group members provided by any other
+// module, e.g. the health checks collected by the health module.
+//
+// # Interface Bindings
+//
+// [Bind] binds an interface to the implementation built by a constructor. Only the interface
+// is registered, so exporting a binding never leaks the concrete type to importing modules:
+//
+//	var userRepository = vara.Bind[UserRepository](newPgUserRepository)
+//
+//	func (m *UserModule) Config() *vara.ModuleConfig {
+//		return &vara.ModuleConfig{
+//			ExportConstructors:   []vara.ProviderConstructor{userRepository},
+//			ProviderConstructors: []vara.ProviderConstructor{userRepository},
+//		}
+//	}
+//
+// Swapping in another implementation, such as an in-memory repository for tests, only
+// changes the binding.
+//
+// # Optional and Lazy Dependencies
+//
+// [Optional] marks a dependency that may not be provided; its value is left as the zero value
+// instead of failing the build. [Lazy] defers resolving a dependency until its Get method is
+// first called, which breaks circular dependencies between providers:
+//
+//	func NewUserService(auth vara.Lazy[*AuthService], cache vara.Optional[*CacheService]) *UserService {
+//		return &UserService{
+//			auth:  auth,
+//			cache: cache.Value,
+//		}
+//	}
+//
+// # Bootstrapping
+//
+// Constructors may accept a context.Context to give up connecting to external systems once
+// building the application is aborted. [NewWithContext] builds the application with the given
+// context and aborts once it is done, or the bootstrap timeout is exceeded:
+//
+//	func newDatabase(ctx context.Context, cfg *config.Service) (*Database, error) {
+//		return connect(ctx, cfg.MustGet("DB_URL"))
+//	}
+//
+//	app, err := vara.NewWithContext(ctx, &app.Module{},
+//		vara.WithConcurrentBuild(),
+//		vara.WithBootstrapTimeout(10*time.Second),
+//	)
+//
+// With [WithConcurrentBuild], every provider is built while the application is created, and
+// the constructors of providers that don't depend on each other are called concurrently.
+//
+// # Logging
+//
+// Every module is provided a *slog.Logger whose records carry a `module` attribute, unless it
+// provides or imports its own. The loggers are children of the application's logger, set with
+// [GlobalLogger], which Vara also logs its own messages with. The logger module configures it
+// from the environment:
+//
+//	Imports: []vara.Module{&logger.Module{AccessLog: true}},
+//
 // # Module System
 //
 // Modules are the building blocks of a Vara application. Each module must implement the [Module] interface:
@@ -106,6 +190,28 @@
 //   - [ProviderConstructors]: Internal services used within the module
 //   - [ControllerConstructors]: HTTP controllers
 //
+// Modules are singletons. A module imported from several places, e.g. a database module imported
+// by multiple feature modules, is built once and its exported instances are shared by every importer.
+// Modules of the same type are considered the same module when their values are equal.
+//
+// A module can re-export the modules it imports by listing them in Exports, so importing it
+// also makes the re-exported modules' exports available:
+//
+//	func (m *CoreModule) Config() *vara.ModuleConfig {
+//		return &vara.ModuleConfig{
+//			Imports: []vara.Module{&cache.Module{}, &database.Module{}},
+//			Exports: []vara.Provider{&cache.Module{}, &database.Module{}},
+//		}
+//	}
+//
+// Modules that import each other form an import cycle, which is reported as [ErrImportCycle]
+// along with the import path. To wire mutually dependent modules on purpose, import one of them
+// through a [ForwardRef]:
+//
+//	Imports: []vara.Module{
+//		vara.ForwardRef(func() vara.Module { return &AuthModule{} }),
+//	}
+//
 // # Lifecycle Management
 //
 // Vara provides hooks for managing component lifecycles:
@@ -129,7 +235,14 @@
 //
 // Lifecycle Events:
 //   - OnStart: Called before the application starts accepting connections
-//   - OnStop: Called during graceful shutdown
+//   - OnPreStop: Called as soon as a graceful shutdown begins, while requests are still served
+//   - OnStop: Called during graceful shutdown, within the timeout set by [WithShutdownTimeout]
+//
+// Hooks are ordered by the dependencies between the providers that appended them: a provider's
+// hooks start after, and stop before, the hooks of the providers it depends on. Hooks of providers
+// that don't depend on each other run concurrently, up to the limit set by [WithLifecycleParallelism].
+// Starting stops at the first failing hook and rolls back the hooks that already started. Each
+// hook may set a Name, used in the returned [LifecycleHookError], and a Timeout for its functions.
 //
 // # Controllers
 //
@@ -184,9 +297,136 @@
 //	    return validated, nil
 //	}
 //
-// Guard Scopes:
-//   - Route-level: Applied to specific routes only
+// Guard Scopes, in the order they run:
+//   - Global: Applied to every route, with [WithGlobalGuards], [GlobalGuard] or [App.UseGlobalGuards]
+//   - Module-level: Applied to all routes of a module's controllers and of the modules it imports.
+//     A module imported by several modules is guarded by the guards of each of them, in import order
 //   - Controller-level: Applied to all routes in a controller
+//   - Route-level: Applied to specific routes only
+//
+// Routes, controllers or modules marked [Public] skip global and module-level guards:
+//
+//	{
+//		Pattern:  "/signin",
+//		Method:   http.MethodPost,
+//		Handler:  http.HandlerFunc(c.signin),
+//		Metadata: vara.Metadata{vara.Public()},
+//	}
+//
+// A guard that denies a request without an error rejects it with a 403 Forbidden. Guards choose
+// another response by returning an [HTTPError], such as [ErrUnauthorized] or one made with
+// [NewHTTPError], along with the headers to set. Other errors are logged and rejected with a 500
+// Internal Server Error, without exposing the error. [GlobalRejectionRenderer] replaces the plain
+// text rejections, e.g. with the JSON responses of the json module:
+//
+//	return false, vara.ErrTooManyRequests.WithHeader("Retry-After", "30")
+//
+// Guards of a scope must all allow a request. [AnyOf], [AllOf], [Not] and [When] combine guards
+// otherwise, taking both guards and guard constructors resolved with the module's dependencies:
+//
+//	Guards: []vara.Guard{
+//		vara.AnyOf(newAPIKeyGuard, newJWTGuard),
+//		vara.When(hasSignature, newSignatureGuard),
+//	}
+//
+// When every guard of [AnyOf] denies a request, their errors are joined, and the request is
+// rejected with the first [HTTPError] among them.
+//
+// The jwt module signs and verifies tokens with keys from a JWKS file or an in-memory key set.
+// Its guard allows requests carrying a valid Bearer token, whose claims handlers read with
+// jwt.ClaimsFromRequest:
+//
+//	ProviderConstructors: []vara.ProviderConstructor{vara.GlobalGuard(jwt.NewGuard)},
+//
+// The auth module authenticates API keys and Basic credentials against a credential store,
+// such as a file of hashed credentials, and emits an event for every failed attempt:
+//
+//	Guards: []vara.Guard{vara.AnyOf(auth.NewAPIKeyGuard, auth.NewBasicGuard)},
+//
+// The throttle module limits requests with a token bucket or a sliding window, per client IP, user
+// or API key. Routes declare their limits with metadata, overriding the module's default policy:
+//
+//	Metadata: vara.Metadata{throttle.Limit(throttle.Policy{Limit: 10, Window: time.Minute})},
+//
+// The authz module authorizes the authenticated principal against the roles, permissions and
+// policies routes require with metadata. Policies and resource loaders are registered as providers:
+//
+//	Guards:   []vara.Guard{vara.AllOf(jwt.NewGuard, authz.NewGuard)},
+//	Metadata: vara.Metadata{authz.Can("update", "post")},
+//
+// The session module keeps sessions in encrypted cookies, or in a session store behind a signed
+// cookie. Handlers access the request's session with the injected session.Accessor, and forms
+// submitted to routes are protected from cross-site request forgery by its guard:
+//
+//	GuardConstructors: []vara.GuardConstructor{session.NewCSRFGuard},
+//
+// A guard implementing [RouteChecker] checks every route it guards when the route is
+// registered, so a route whose metadata the guard can't enforce fails [New] with an error
+// rather than its requests, as with the throttle module's policies.
+//
+// # Request Context
+//
+// Every request handled by a route carries a [Context], retrieved with [FromRequest], describing
+// the route, its metadata and path params. Its typed store lets guards pass values, such as the
+// authenticated user, on to interceptors and handlers:
+//
+//	var userKey = vara.NewKey[*User]("user")
+//
+//	userKey.Set(gCtx.Context(), user)              // in a guard
+//	user, ok := userKey.Get(vara.FromRequest(r))   // in a handler
+//
+// # Metadata
+//
+// Routes, controllers and modules may carry [Metadata] built from [SetMetadata] entries, which
+// lends itself to decorator-style helpers. A [Reflector], available to every provider, reads
+// it back, and the generic [Get] and [GetAllAndOverride] read it as a given type; the latter
+// lets route metadata override controller and module metadata:
+//
+//	func Public() vara.MetadataEntry { return vara.SetMetadata("public", true) }
+//
+//	func (g *AuthGuard) Allow(gCtx vara.GuardContext) (bool, error) {
+//		isPublic, _ := vara.GetAllAndOverride[bool](g.reflector, gCtx.Context(), "public")
+//		if isPublic {
+//			return true, nil
+//		}
+//		...
+//	}
+//
+// # Interceptors
+//
+// Interceptors wrap the handling of requests on every route of the application, around the
+// route's guards and handler, e.g. to instrument or log requests. An interceptor must implement
+// the [Interceptor] interface and is registered with [GlobalInterceptor]:
+//
+//	func (i *TimingInterceptor) Intercept(route vara.RouteInfo, next http.Handler) http.Handler {
+//		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//			start := time.Now()
+//			next.ServeHTTP(w, r)
+//			i.record(route.Path, time.Since(start))
+//		})
+//	}
+//
+// [RejectingGuard] reports which guard, if any, rejected a request.
+//
+// A [RouteObserver], registered with [GlobalRouteObserver], is notified when a request is
+// received and when each interceptor and guard handles it, e.g. to trace requests as the
+// tracing module does.
+//
+// # CORS
+//
+// [WithCORS] configures Cross-Origin Resource Sharing for every route, and the CORS field of
+// a module's or controller's config overrides it for their routes. Preflight OPTIONS requests
+// are answered automatically, before any guard runs:
+//
+//	app, err := vara.New(&app.Module{}, vara.WithCORS(vara.CORSConfig{
+//		AllowedOrigins:   []string{"https://app.example.com", "https://*.example.com"},
+//		AllowCredentials: true,
+//		MaxAge:           10 * time.Minute,
+//	}))
+//
+// Configs allowing credentials from any origin ("*") are rejected, and origin patterns
+// are anchored to match whole origins. A module imported by modules with different configs
+// must set its own, since its routes are only registered once.
 //
 // # Complete application structure:
 //
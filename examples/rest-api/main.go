@@ -1,3 +1,5 @@
+// Command rest-api is an example application built with Vara. It reads DB_URL and
+// JWT_SECRET from a .env file in the directory it is run from; see .env.example.
 package main
 
 import (
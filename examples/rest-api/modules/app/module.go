@@ -5,6 +5,7 @@ import (
 	"github.com/huboh/vara/pkg/modules/config"
 	"github.com/huboh/vara/pkg/modules/event"
 	"github.com/huboh/vara/pkg/modules/json"
+	"github.com/huboh/vara/pkg/modules/logger"
 
 	"github.com/huboh/vara/examples/rest-api/modules/auth"
 	"github.com/huboh/vara/examples/rest-api/modules/database"
@@ -17,11 +18,15 @@ func (mod *Module) Config() *vara.ModuleConfig {
 	return &vara.ModuleConfig{
 		Imports: []vara.Module{
 			&config.Module{},
+			&logger.Module{AccessLog: true},
 			&event.Module{},
 			&database.Module{},
 			&json.Module{IsGlobal: true},
 			&auth.Module{},
 			&user.Module{},
 		},
+		ProviderConstructors: []vara.ProviderConstructor{
+			vara.GlobalRejectionRenderer(json.NewRejectionRenderer),
+		},
 	}
 }
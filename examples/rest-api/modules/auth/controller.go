@@ -27,18 +27,15 @@ func (c *controller) Config() *vara.ControllerConfig {
 				Pattern:  "/signin",
 				Method:   http.MethodPost,
 				Handler:  http.HandlerFunc(c.handleSignin),
-				Metadata: map[string]string{},
+				Metadata: vara.Metadata{vara.Public()},
 			},
 			{
 				Pattern:  "/signup",
 				Method:   http.MethodPost,
 				Handler:  http.HandlerFunc(c.handleSignup),
-				Metadata: map[string]string{},
+				Metadata: vara.Metadata{vara.Public()},
 			},
 		},
-		GuardConstructors: []vara.GuardConstructor{
-			newGuard,
-		},
 	}
 }
 
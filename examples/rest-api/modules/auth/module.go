@@ -2,12 +2,21 @@ package auth
 
 import (
 	"github.com/huboh/vara"
+	"github.com/huboh/vara/pkg/modules/jwt"
 )
 
 type Module struct{}
 
 func (m *Module) Config() *vara.ModuleConfig {
 	return &vara.ModuleConfig{
+		Imports: []vara.Module{
+			&jwt.Module{},
+		},
+		// routes marked vara.Public() skip module guards,
+		// so only sign in and sign up don't require a token.
+		GuardConstructors: []vara.GuardConstructor{
+			jwt.NewGuard,
+		},
 		ProviderConstructors: []vara.ProviderConstructor{
 			newService,
 			newListener,
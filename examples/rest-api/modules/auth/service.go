@@ -4,17 +4,20 @@ import (
 	"context"
 
 	"github.com/huboh/vara/pkg/modules/event"
+	"github.com/huboh/vara/pkg/modules/jwt"
 
 	"github.com/huboh/vara/examples/rest-api/modules/database"
 )
 
 type service struct {
+	jwt      *jwt.Service
 	events   *event.Service
 	database *database.Service
 }
 
-func newService(e *event.Service, d *database.Service, l *listener) *service {
+func newService(e *event.Service, d *database.Service, j *jwt.Service, l *listener) *service {
 	return &service{
+		jwt:      j,
 		events:   e,
 		database: d,
 	}
@@ -29,6 +32,12 @@ func (s *service) signin() map[string]string {
 		// handle err
 	}
 
+	token, err := s.jwt.Sign(jwt.Claims{jwt.ClaimSubject: user["id"]})
+	if err != nil {
+		// handle err
+	}
+	user["token"] = token
+
 	return user
 }
 
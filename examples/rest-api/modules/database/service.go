@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/huboh/vara/pkg/modules/config"
@@ -10,9 +11,9 @@ type Service struct {
 	configs *config.Service
 }
 
-func newService(c *config.Service) (*Service, error) {
+func newService(ctx context.Context, c *config.Service) (*Service, error) {
 	db := &Service{configs: c}
-	err := db.connect(c.MustGet("DB_URL"))
+	err := db.connect(ctx, c.MustGet("DB_URL"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -20,14 +21,14 @@ func newService(c *config.Service) (*Service, error) {
 	return db, nil
 }
 
-func (db *Service) connect(string) error {
-	return nil
+func (db *Service) connect(ctx context.Context, _ string) error {
+	return ctx.Err()
 }
 
 func (db *Service) Transaction(f func() error) error {
 	err := f()
 	if err != nil {
-		fmt.Errorf("database transaction failed: %w", err)
+		return fmt.Errorf("database transaction failed: %w", err)
 	}
 
 	return nil
@@ -2,7 +2,7 @@ package user
 
 import (
 	"context"
-	"fmt"
+	"log/slog"
 
 	"github.com/huboh/vara"
 	"github.com/huboh/vara/pkg/modules/event"
@@ -11,11 +11,13 @@ import (
 )
 
 type listener struct {
+	logger   *slog.Logger
 	database *database.Service
 }
 
-func newListener(e *event.Service, d *database.Service, lc *vara.Lifecycle) *listener {
+func newListener(e *event.Service, d *database.Service, lc *vara.Lifecycle, lg *slog.Logger) *listener {
 	l := &listener{
+		logger:   lg,
 		database: d,
 	}
 
@@ -43,11 +45,11 @@ func newListener(e *event.Service, d *database.Service, lc *vara.Lifecycle) *lis
 }
 
 func (l *listener) onUserSignup(e event.Event) error {
-	fmt.Println("handling user signup event for user: %v", e.Payload)
+	l.logger.Info("handling user signup event", "user", e.Payload)
 	return nil
 }
 
 func (l *listener) onUserSignin(e event.Event) error {
-	fmt.Println("handling user signin event for user: %v", e.Payload)
+	l.logger.Info("handling user signin event", "user", e.Payload)
 	return nil
 }
@@ -0,0 +1,345 @@
+package vara
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"go.uber.org/dig"
+)
+
+var (
+	inType    = reflect.TypeOf(dig.In{})
+	outType   = reflect.TypeOf(dig.Out{})
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// exportKey identifies a value exported by a provider constructor. Grouped values exported by
+// a module are registered under a name in its scope, which is set once they are registered.
+type exportKey struct {
+	typ     reflect.Type
+	name    string
+	group   string
+	flatten bool
+}
+
+// tag returns the struct tag used to request the value from the exporting module's scope.
+func (k exportKey) tag() reflect.StructTag {
+	if k.name == "" {
+		return ""
+	}
+	return reflect.StructTag(fmt.Sprintf(`name:"%s"`, k.name))
+}
+
+// resultTag returns the struct tag the value is registered with in the scopes it is forwarded to.
+func (k exportKey) resultTag() reflect.StructTag {
+	switch {
+	case k.flatten:
+		return reflect.StructTag(fmt.Sprintf(`group:"%s,flatten"`, k.group))
+	case k.group != "":
+		return reflect.StructTag(fmt.Sprintf(`group:"%s"`, k.group))
+	default:
+		return k.tag()
+	}
+}
+
+// valueType returns the type of the values registered under the key, the type of the elements
+// of flattened groups.
+func (k exportKey) valueType() reflect.Type {
+	if k.flatten {
+		return k.typ.Elem()
+	}
+	return k.typ
+}
+
+// unnamed returns the key without the name a grouped value is registered under.
+func (k exportKey) unnamed() exportKey {
+	if k.group != "" {
+		k.name = ""
+	}
+	return k
+}
+
+// getProviderSpec returns the provider constructor's annotations.
+func getProviderSpec(ctor ProviderConstructor) *providerSpec {
+	spec, ok := ctor.(*providerSpec)
+	if !ok {
+		spec = &providerSpec{ctor: ctor}
+	}
+	return spec
+}
+
+// getExportKeys returns the keys of the values that the provider constructor registers,
+// taking its [Provide] annotations into account. It reports false if the values can't be
+// determined, e.g. because the constructor returns nested `dig.Out` structs.
+func getExportKeys(ctor ProviderConstructor) ([]exportKey, bool) {
+	spec := getProviderSpec(ctor)
+
+	fnType := reflect.TypeOf(spec.ctor)
+	if (fnType == nil) || (fnType.Kind() != reflect.Func) {
+		return nil, false
+	}
+
+	var keys []exportKey
+	for i := 0; i < fnType.NumOut(); i++ {
+		out := fnType.Out(i)
+		if out == errorType {
+			continue
+		}
+		if dig.IsOut(out) {
+			outKeys, ok := getOutKeys(out)
+			if !ok {
+				return nil, false
+			}
+			keys = append(keys, outKeys...)
+			continue
+		}
+
+		types := []reflect.Type{out}
+		if len(spec.as) > 0 {
+			types = nil
+			for _, iface := range spec.as {
+				types = append(types, reflect.TypeOf(iface).Elem())
+			}
+			if spec.group != "" {
+				// grouped values are only registered as the first interface
+				types = types[:1]
+			}
+		}
+
+		for _, t := range types {
+			keys = append(keys, exportKey{typ: t, name: spec.name, group: spec.group})
+		}
+	}
+
+	return keys, len(keys) > 0
+}
+
+// getOutKeys returns the keys of the values registered by the fields of a `dig.Out` struct.
+// It reports false if the struct nests other `dig.Out` structs.
+func getOutKeys(out reflect.Type) ([]exportKey, bool) {
+	var keys []exportKey
+	for i := 0; i < out.NumField(); i++ {
+		f := out.Field(i)
+		if ((f.Anonymous) && (f.Type == outType)) || (!f.IsExported()) {
+			continue
+		}
+		if dig.IsOut(f.Type) {
+			return nil, false
+		}
+
+		group, opts, _ := strings.Cut(f.Tag.Get("group"), ",")
+		keys = append(keys, exportKey{
+			typ:     f.Type,
+			name:    f.Tag.Get("name"),
+			group:   group,
+			flatten: (opts == "flatten"),
+		})
+	}
+	return keys, true
+}
+
+// hasGroup reports whether one of the keys identifies a grouped value.
+func hasGroup(keys []exportKey) bool {
+	return slices.ContainsFunc(keys, func(k exportKey) bool { return k.group != "" })
+}
+
+// overlaps reports whether the keys identify one of the values identified by others.
+func overlaps(keys, others []exportKey) bool {
+	return slices.ContainsFunc(keys, func(k exportKey) bool { return slices.Contains(others, k.unnamed()) })
+}
+
+// renameExportedGroups returns a constructor calling ctor, which returns `dig.Out` structs,
+// whose results register the values of the structs' grouped fields under the names returned
+// by newName instead, along with the keys of the renamed values.
+func renameExportedGroups(ctor constructor, newName func(group string) string) (constructor, []exportKey) {
+	var (
+		fnType  = reflect.TypeOf(ctor)
+		keys    []exportKey
+		outs    []reflect.Type
+		renamed = map[int][]int{} // the fields copied from each renamed result
+	)
+
+	for i := 0; i < fnType.NumOut(); i++ {
+		out := fnType.Out(i)
+		outs = append(outs, out)
+		if !dig.IsOut(out) {
+			continue
+		}
+
+		fields := []reflect.StructField{{Name: outType.Name(), Type: outType, Anonymous: true}}
+		for j := 0; j < out.NumField(); j++ {
+			f := out.Field(j)
+			if ((f.Anonymous) && (f.Type == outType)) || (!f.IsExported()) {
+				continue
+			}
+
+			if group, opts, _ := strings.Cut(f.Tag.Get("group"), ","); group != "" {
+				key := exportKey{typ: f.Type, name: newName(group), group: group, flatten: (opts == "flatten")}
+				keys = append(keys, key)
+				f.Tag = key.tag()
+			}
+			fields = append(fields, reflect.StructField{Name: f.Name, Type: f.Type, Tag: f.Tag})
+			renamed[i] = append(renamed[i], j)
+		}
+		outs[i] = reflect.StructOf(fields)
+	}
+
+	var ins []reflect.Type
+	for i := 0; i < fnType.NumIn(); i++ {
+		ins = append(ins, fnType.In(i))
+	}
+
+	fn := reflect.ValueOf(ctor)
+	wrapped := reflect.MakeFunc(reflect.FuncOf(ins, outs, fnType.IsVariadic()), func(args []reflect.Value) []reflect.Value {
+		var results []reflect.Value
+		if fnType.IsVariadic() {
+			results = fn.CallSlice(args)
+		} else {
+			results = fn.Call(args)
+		}
+
+		for i, fields := range renamed {
+			v := reflect.New(outs[i]).Elem()
+			for k, j := range fields {
+				v.Field(k + 1).Set(results[i].Field(j))
+			}
+			results[i] = v
+		}
+		return results
+	})
+
+	return wrapped.Interface(), keys
+}
+
+// newExportForwarder returns a constructor that resolves the value identified by key from
+// the exporting module's scope. Providing it in an importing module's scope lets every
+// importer share the exporting module's instance instead of building its own.
+func newExportForwarder(s scope, key exportKey) constructor {
+	var (
+		params  = newForwardedParams(key)
+		results = newForwardedResults(key)
+	)
+
+	fwdType := reflect.FuncOf(nil, []reflect.Type{results, errorType}, false)
+	fwd := reflect.MakeFunc(fwdType, func([]reflect.Value) []reflect.Value {
+		var result = reflect.New(results).Elem()
+
+		resolve := reflect.MakeFunc(
+			reflect.FuncOf([]reflect.Type{params}, nil, false),
+			func(args []reflect.Value) []reflect.Value {
+				result.Field(1).Set(args[0].Field(1))
+				return nil
+			},
+		)
+
+		err := s.Invoke(resolve.Interface())
+		errVal := reflect.New(errorType).Elem()
+		if err != nil {
+			errVal.Set(reflect.ValueOf(err))
+		}
+
+		return []reflect.Value{result, errVal}
+	})
+
+	return fwd.Interface()
+}
+
+// newGroupForwarder returns a constructor that adds the value registered
+// under the export name of a grouped provider to the provider's group.
+func newGroupForwarder(key exportKey) constructor {
+	var (
+		params  = newForwardedParams(key)
+		results = newForwardedResults(key)
+	)
+
+	fwdType := reflect.FuncOf([]reflect.Type{params}, []reflect.Type{results}, false)
+	fwd := reflect.MakeFunc(fwdType, func(args []reflect.Value) []reflect.Value {
+		result := reflect.New(results).Elem()
+		result.Field(1).Set(args[0].Field(1))
+		return []reflect.Value{result}
+	})
+
+	return fwd.Interface()
+}
+
+// newForwardedParams returns the `dig.In` struct requesting the value identified by key.
+func newForwardedParams(key exportKey) reflect.Type {
+	return reflect.StructOf([]reflect.StructField{
+		{Name: inType.Name(), Type: inType, Anonymous: true},
+		{Name: "Value", Type: key.typ, Tag: key.tag()},
+	})
+}
+
+// newForwardedResults returns the `dig.Out` struct registering the value identified by key
+// in the scopes it is forwarded to.
+func newForwardedResults(key exportKey) reflect.Type {
+	return reflect.StructOf([]reflect.StructField{
+		{Name: outType.Name(), Type: outType, Anonymous: true},
+		{Name: "Value", Type: key.typ, Tag: key.resultTag()},
+	})
+}
+
+// groupExport is a grouped value exported by a module, with the modules it is forwarded to.
+type groupExport struct {
+	exporter *module
+	key      exportKey
+	targets  []*module
+}
+
+// groupExports collects the grouped values exported by an application's modules until every
+// module is built. Resolving a group also collects values from ancestor scopes, so a value is
+// only forwarded to the modules that don't already see it through an ancestor it's forwarded to.
+type groupExports struct {
+	exports []*groupExport
+	names   int
+}
+
+// newName returns a name, unique in the application, that a grouped value exported
+// by a module is registered under in its scope.
+func (g *groupExports) newName(group string) string {
+	g.names++
+	return fmt.Sprintf("vara.export.%s.%d", group, g.names)
+}
+
+// add records that the value identified by key, exported by the exporter, is forwarded to the target.
+func (g *groupExports) add(exporter *module, key exportKey, target *module) {
+	var export *groupExport
+	for _, e := range g.exports {
+		if (e.exporter == exporter) && (e.key == key) {
+			export = e
+			break
+		}
+	}
+	if export == nil {
+		export = &groupExport{exporter: exporter, key: key}
+		g.exports = append(g.exports, export)
+	}
+
+	if !slices.Contains(export.targets, target) {
+		export.targets = append(export.targets, target)
+	}
+}
+
+// register forwards every exported grouped value to the scopes of its targets.
+func (g *groupExports) register() error {
+	for _, e := range g.exports {
+		for _, target := range e.targets {
+			if target._hasAncestorIn(e.targets) {
+				continue
+			}
+
+			var err error
+			if target == e.exporter {
+				err = target.scope.Provide(newGroupForwarder(e.key))
+			} else {
+				err = target.scope.Provide(newExportForwarder(e.exporter.scope, e.key))
+			}
+			if err != nil {
+				return fmt.Errorf("error forwarding export (%s) of module (%T): %w", e.key.group, e.exporter.Module, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package vara
+
+import (
+	"slices"
+	"testing"
+
+	"go.uber.org/dig"
+)
+
+type itemsInput struct {
+	In
+
+	Items []string `group:"items"`
+}
+
+// itemsController records the members of the items group seen from its module's scope.
+type itemsController struct{}
+
+func (itemsController) Config() *ControllerConfig { return &ControllerConfig{} }
+
+func newItemA() string { return "a" }
+func newItemB() string { return "b" }
+
+type itemsModule struct {
+	seen *[]string
+}
+
+func (m *itemsModule) Config() *ModuleConfig {
+	a, b := Provide(newItemA, Group("items")), Provide(newItemB, Group("items"))
+	return &ModuleConfig{
+		ExportConstructors:   []ProviderConstructor{a, b},
+		ProviderConstructors: []ProviderConstructor{a, b},
+		ControllerConstructors: []ControllerConstructor{
+			func(in itemsInput) itemsController {
+				*m.seen = in.Items
+				return itemsController{}
+			},
+		},
+	}
+}
+
+type itemsImporter struct {
+	seen    *[]string
+	imports []Module
+}
+
+func (m *itemsImporter) Config() *ModuleConfig {
+	return &ModuleConfig{
+		Imports: m.imports,
+		ControllerConstructors: []ControllerConstructor{
+			func(in itemsInput) itemsController {
+				*m.seen = in.Items
+				return itemsController{}
+			},
+		},
+	}
+}
+
+func TestExportedGroupIsSeenOnce(t *testing.T) {
+	var exporterSeen, rootSeen, siblingSeen []string
+
+	exporter := &itemsModule{seen: &exporterSeen}
+	sibling := &itemsImporter{seen: &siblingSeen, imports: []Module{exporter}}
+	root := &itemsImporter{seen: &rootSeen, imports: []Module{exporter, sibling}}
+
+	_, err := New(root)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for name, seen := range map[string][]string{"exporter": exporterSeen, "root": rootSeen, "sibling": siblingSeen} {
+		slices.Sort(seen)
+		if !slices.Equal(seen, []string{"a", "b"}) {
+			t.Errorf("%s module resolved items %v, want [a b]", name, seen)
+		}
+	}
+}
+
+// newItem returns a constructor of the item, so the constructors it returns share their code.
+func newItem(item string) func() string {
+	return func() string { return item }
+}
+
+type closuresModule struct {
+	seen *[]string
+}
+
+func (m *closuresModule) Config() *ModuleConfig {
+	a, b := Provide(newItem("a"), Group("items")), Provide(newItem("b"), Group("items"))
+	return &ModuleConfig{
+		ExportConstructors:   []ProviderConstructor{a, b},
+		ProviderConstructors: []ProviderConstructor{a, b},
+		ControllerConstructors: []ControllerConstructor{
+			func(in itemsInput) itemsController {
+				*m.seen = in.Items
+				return itemsController{}
+			},
+		},
+	}
+}
+
+func TestExportedGroupClosures(t *testing.T) {
+	var exporterSeen, rootSeen []string
+
+	exporter := &closuresModule{seen: &exporterSeen}
+	root := &itemsImporter{seen: &rootSeen, imports: []Module{exporter}}
+
+	_, err := New(root)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for name, seen := range map[string][]string{"exporter": exporterSeen, "root": rootSeen} {
+		slices.Sort(seen)
+		if !slices.Equal(seen, []string{"a", "b"}) {
+			t.Errorf("%s module resolved items %v, want [a b]", name, seen)
+		}
+	}
+}
+
+type (
+	outA struct{}
+	outB struct{}
+)
+
+type outResults struct {
+	dig.Out
+
+	A     *outA
+	B     *outB    `name:"b"`
+	Item  string   `group:"items"`
+	Items []string `group:"items,flatten"`
+}
+
+type outInput struct {
+	In
+
+	A     *outA
+	B     *outB    `name:"b"`
+	Items []string `group:"items"`
+}
+
+// outModule exports the values of a `dig.Out` struct, counting the calls to its constructor.
+type outModule struct {
+	calls *int
+	seen  *[]string
+}
+
+func (m *outModule) Config() *ModuleConfig {
+	ctor := func() outResults {
+		*m.calls++
+		return outResults{A: &outA{}, B: &outB{}, Item: "a", Items: []string{"b", "c"}}
+	}
+
+	return &ModuleConfig{
+		ExportConstructors:   []ProviderConstructor{ctor},
+		ProviderConstructors: []ProviderConstructor{ctor},
+		ControllerConstructors: []ControllerConstructor{
+			func(in outInput) itemsController {
+				*m.seen = in.Items
+				return itemsController{}
+			},
+		},
+	}
+}
+
+// outImporter records the values of the `dig.Out` struct it resolves.
+type outImporter struct {
+	imports []Module
+	seen    *[]string
+	values  *[]*outA
+}
+
+func (m *outImporter) Config() *ModuleConfig {
+	return &ModuleConfig{
+		Imports: m.imports,
+		ControllerConstructors: []ControllerConstructor{
+			func(in outInput) itemsController {
+				*m.seen = in.Items
+				*m.values = append(*m.values, in.A)
+				return itemsController{}
+			},
+		},
+	}
+}
+
+func TestExportedOutStruct(t *testing.T) {
+	var (
+		calls                               int
+		values                              []*outA
+		exporterSeen, rootSeen, siblingSeen []string
+	)
+
+	exporter := &outModule{calls: &calls, seen: &exporterSeen}
+	sibling := &outImporter{imports: []Module{exporter}, seen: &siblingSeen, values: &values}
+	root := &outImporter{imports: []Module{exporter, sibling}, seen: &rootSeen, values: &values}
+
+	_, err := New(root)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if (calls != 1) || (len(values) != 2) || (values[0] != values[1]) {
+		t.Errorf("constructor called %d times, importers resolved %v, want a single shared instance", calls, values)
+	}
+
+	for name, seen := range map[string][]string{"exporter": exporterSeen, "root": rootSeen, "sibling": siblingSeen} {
+		slices.Sort(seen)
+		if !slices.Equal(seen, []string{"a", "b", "c"}) {
+			t.Errorf("%s module resolved items %v, want [a b c]", name, seen)
+		}
+	}
+}
+
+type exportOnly struct{}
+
+// exportOnlyModule exports a value without listing its constructor among its providers.
+type exportOnlyModule struct {
+	calls *int
+}
+
+func (m *exportOnlyModule) Config() *ModuleConfig {
+	return &ModuleConfig{
+		ExportConstructors: []ProviderConstructor{
+			func() *exportOnly {
+				*m.calls++
+				return &exportOnly{}
+			},
+		},
+	}
+}
+
+func TestExportOnlyConstructor(t *testing.T) {
+	var (
+		calls  int
+		values []*exportOnly
+	)
+
+	resolve := func(v *exportOnly) *testController {
+		values = append(values, v)
+		return &testController{}
+	}
+
+	var (
+		exporter = &exportOnlyModule{calls: &calls}
+		sibling  = &importingCtorModule{
+			ctorModule: ctorModule{controllers: []ControllerConstructor{resolve}},
+			imports:    []Module{exporter},
+		}
+		root = &importingCtorModule{
+			ctorModule: ctorModule{controllers: []ControllerConstructor{resolve}},
+			imports:    []Module{sibling, exporter},
+		}
+	)
+
+	_, err := New(root)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if (calls != 1) || (len(values) != 2) || (values[0] != values[1]) {
+		t.Errorf("constructor called %d times, importers resolved %v, want a single shared instance", calls, values)
+	}
+}
+
+// importingCtorModule is a [ctorModule] importing modules.
+type importingCtorModule struct {
+	ctorModule
+	imports []Module
+}
+
+func (m *importingCtorModule) Config() *ModuleConfig {
+	cfg := m.ctorModule.Config()
+	cfg.Imports = m.imports
+	return cfg
+}
@@ -0,0 +1,37 @@
+package vara
+
+// forwardRef is a reference to a module that is only resolved when it is imported.
+type forwardRef struct {
+	resolve func() Module
+}
+
+// ForwardRef creates a reference to a module that is resolved when the importing
+// module is built rather than when its config is declared.
+//
+// A forward reference allows two modules to import each other on purpose. When the
+// referenced module is already being built further up the import chain, its providers
+// are already visible to the importing module and it is not built again, so the
+// import cycle is not reported as an error.
+//
+// Example:
+//
+//	func (m *UserModule) Config() *vara.ModuleConfig {
+//		return &vara.ModuleConfig{
+//			Imports: []vara.Module{
+//				vara.ForwardRef(func() vara.Module { return &AuthModule{} }),
+//			},
+//		}
+//	}
+//
+// Providers that depend on each other across such modules should use [Lazy] to break
+// the dependency cycle.
+func ForwardRef(fn func() Module) Module {
+	return &forwardRef{
+		resolve: fn,
+	}
+}
+
+// Config returns the config of the referenced module.
+func (f *forwardRef) Config() *ModuleConfig {
+	return f.resolve().Config()
+}
@@ -17,13 +17,16 @@ const (
 	groupProviders    group = "providers"
 	groupControllers  group = "controllers"
 	groupInterceptors group = "interceptors"
+
+	groupGlobalGuards   group = "global.guards"
+	groupRouteObservers group = "route.observers"
 )
 
-// guardGroupInput is used for injecting the collection of [Guard] instances
-// grouped under `groupGuards` in a particular DI scope.
-type guardGroupInput struct {
+// globalGuardGroupInput is used for injecting the collection of [Guard] instances
+// grouped under `groupGlobalGuards` in a particular DI scope.
+type globalGuardGroupInput struct {
 	dig.In
-	Guards []Guard `group:"guards"`
+	Guards []Guard `group:"global.guards"`
 }
 
 // providerGroupInput is used for injecting the collection of [Provider] instances
@@ -1,6 +1,10 @@
 package vara
 
-import "net/http"
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
 
 // Guard is an interface that determines whether a request should be handled by
 // a route handler or rejected based on specific criteria or metadata present at runtime.
@@ -8,6 +12,14 @@ type Guard interface {
 	Allow(GuardContext) (bool, error)
 }
 
+// RouteChecker is implemented by guards that check the routes they guard when the routes are
+// registered, e.g. to validate the metadata the guard reads, so misconfigured routes fail [New]
+// rather than their requests. Guards combined by [AllOf], [AnyOf], [Not] and [When] are checked
+// as well.
+type RouteChecker interface {
+	CheckRoute(route RouteInfo) error
+}
+
 // GuardContext provides the contextual information that a guard needs to make
 // its decision.
 //
@@ -24,6 +36,11 @@ type GuardContext struct {
 	ControllerConfig ControllerConfig
 }
 
+// Context returns the [Context] of the request, e.g. to store values for the route's handler.
+func (g GuardContext) Context() *Context {
+	return FromRequest(g.Http.R)
+}
+
 // GuardContextHttp holds HTTP request and response information for GuardContext.
 type GuardContextHttp struct {
 	R *http.Request
@@ -59,3 +76,120 @@ func newGuard(g Guard) (*guard, error) {
 		Guard: g,
 	}, nil
 }
+
+// resolveGuards returns the guards, followed by the guards built by the constructors
+// with their dependencies resolved from the scope. Guard combinators are resolved as well.
+func resolveGuards(s scope, guards []Guard, ctors []GuardConstructor) ([]*guard, error) {
+	var resolved []Guard
+	for _, grd := range guards {
+		g, err := resolveGuard(s, grd)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, g)
+	}
+
+	for _, grdCtor := range ctors {
+		results, err := callConstructor(s, grdCtor)
+		if err != nil {
+			return nil, fmt.Errorf("error building guard (%T): %w", grdCtor, err)
+		}
+
+		for _, result := range results {
+			grd, ok := result.Interface().(Guard)
+			if !ok {
+				return nil, fmt.Errorf("guard constructor (%T) returned a non-guard value (%s)", grdCtor, result.Type())
+			}
+
+			g, err := resolveGuard(s, grd)
+			if err != nil {
+				return nil, err
+			}
+			resolved = append(resolved, g)
+		}
+	}
+
+	wrapped := make([]*guard, 0, len(resolved))
+	for _, grd := range resolved {
+		g, err := newGuard(grd)
+		if err != nil {
+			return nil, err
+		}
+		wrapped = append(wrapped, g)
+	}
+
+	return wrapped, nil
+}
+
+// checkRoute checks the route with the guards implementing [RouteChecker].
+func checkRoute(guards []*guard, route RouteInfo) error {
+	for _, g := range guards {
+		c, ok := g.Guard.(RouteChecker)
+		if !ok {
+			continue
+		}
+
+		err := c.CheckRoute(route)
+		if err != nil {
+			return fmt.Errorf("guard (%T) rejected route (%s %s): %w", g.Guard, route.Method, route.Path, err)
+		}
+	}
+	return nil
+}
+
+// globalGuards holds the guards applied to every route of the application.
+type globalGuards struct {
+	mu     sync.RWMutex
+	guards []*guard
+	routes []RouteInfo // routes the guards apply to, checked by guards added later
+}
+
+func newGlobalGuards() *globalGuards {
+	return &globalGuards{}
+}
+
+// add applies the guards to every route, once they checked the routes registered so far.
+func (g *globalGuards) add(guards ...*guard) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, route := range g.routes {
+		err := checkRoute(guards, route)
+		if err != nil {
+			return err
+		}
+	}
+
+	g.guards = append(g.guards, guards...)
+	return nil
+}
+
+// guardRoute applies the guards to the route, once they checked it.
+func (g *globalGuards) guardRoute(route RouteInfo) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	err := checkRoute(g.guards, route)
+	if err != nil {
+		return err
+	}
+
+	g.routes = append(g.routes, route)
+	return nil
+}
+
+func (g *globalGuards) get() []*guard {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.guards
+}
+
+// GlobalGuard annotates a guard constructor so the guard is applied to every route of
+// the application. It can be listed in ProviderConstructors of any module.
+//
+// Global guards run before the guards of modules, controllers and routes, and are
+// skipped on routes marked [Public].
+func GlobalGuard(ctor GuardConstructor) ProviderConstructor {
+	return Provide(ctor, Group(groupGlobalGuards.String()), As(new(Guard)), Global())
+}
@@ -0,0 +1,213 @@
+package vara
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrGuardNotResolved indicates a guard combinator was used before the guard
+	// constructors it combines were resolved, i.e. outside of a route.
+	ErrGuardNotResolved = errors.New("guard constructors are not resolved")
+)
+
+// guardResolver is implemented by guards that combine guard constructors
+// which must be resolved with dependencies from the scope of their route.
+type guardResolver interface {
+	// resolveGuard returns a copy of the guard with its guard constructors resolved.
+	resolveGuard(s scope) (Guard, error)
+}
+
+// combinator is the way a combined guard decides on its guards' decisions.
+type combinator string
+
+// recognized combinator
+const (
+	combinatorAllOf = combinator("AllOf")
+	combinatorAnyOf = combinator("AnyOf")
+	combinatorNot   = combinator("Not")
+	combinatorWhen  = combinator("When")
+)
+
+// combinedGuard is a guard combining the decisions of other guards.
+type combinedGuard struct {
+	kind     combinator
+	when     func(GuardContext) bool
+	guards   []GuardConstructor
+	resolved []Guard
+}
+
+// AllOf returns a guard that allows a request if all of the guards allow it, checking them in
+// order and stopping at the first that denies it. Each guard is either a [Guard] or a
+// [GuardConstructor] resolved with the dependencies available to the route.
+func AllOf(guards ...GuardConstructor) Guard {
+	return newCombinedGuard(combinatorAllOf, nil, guards)
+}
+
+// AnyOf returns a guard that allows a request if any of the guards allows it, checking them
+// in order and stopping at the first that allows it, e.g. to accept either an API key or a JWT.
+// Each guard is either a [Guard] or a [GuardConstructor] resolved with the dependencies
+// available to the route.
+//
+// If every guard denies the request, the errors of the guards are joined into the returned
+// error, so the request is rejected with the first [HTTPError] among them.
+func AnyOf(guards ...GuardConstructor) Guard {
+	return newCombinedGuard(combinatorAnyOf, nil, guards)
+}
+
+// Not returns a guard that allows a request if the guard denies it. A guard denies a request
+// by returning false or an [HTTPError]; other errors are returned as is.
+func Not(guard GuardConstructor) Guard {
+	return newCombinedGuard(combinatorNot, nil, []GuardConstructor{guard})
+}
+
+// When returns a guard that applies the guard only to requests the predicate matches,
+// and allows every other request, e.g. to check a signature only if a header is present.
+func When(pred func(GuardContext) bool, guard GuardConstructor) Guard {
+	return newCombinedGuard(combinatorWhen, pred, []GuardConstructor{guard})
+}
+
+func newCombinedGuard(kind combinator, when func(GuardContext) bool, guards []GuardConstructor) *combinedGuard {
+	g := &combinedGuard{
+		kind:   kind,
+		when:   when,
+		guards: guards,
+	}
+
+	// guards combining only guard instances can be used without being resolved
+	resolved := make([]Guard, 0, len(guards))
+	for _, item := range guards {
+		grd, ok := item.(Guard)
+		if !ok {
+			return g
+		}
+		if c, ok := grd.(*combinedGuard); ok && (c.resolved == nil) {
+			return g
+		}
+		resolved = append(resolved, grd)
+	}
+	g.resolved = resolved
+
+	return g
+}
+
+func (g *combinedGuard) resolveGuard(s scope) (Guard, error) {
+	resolved := make([]Guard, 0, len(g.guards))
+	for _, item := range g.guards {
+		grd, err := resolveGuard(s, item)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", g.kind, err)
+		}
+		resolved = append(resolved, grd)
+	}
+
+	return &combinedGuard{
+		kind:     g.kind,
+		when:     g.when,
+		guards:   g.guards,
+		resolved: resolved,
+	}, nil
+}
+
+func (g *combinedGuard) Allow(gCtx GuardContext) (bool, error) {
+	if g.resolved == nil {
+		return false, fmt.Errorf("%s: %w", g.kind, ErrGuardNotResolved)
+	}
+
+	switch g.kind {
+	case combinatorAnyOf:
+		return g.allowAny(gCtx)
+	case combinatorNot:
+		return g.allowNot(gCtx)
+	case combinatorWhen:
+		if (g.when != nil) && (!g.when(gCtx)) {
+			return true, nil
+		}
+		return g.allowAll(gCtx)
+	default:
+		return g.allowAll(gCtx)
+	}
+}
+
+// CheckRoute checks the route with the combined guards implementing [RouteChecker].
+func (g *combinedGuard) CheckRoute(route RouteInfo) error {
+	for _, grd := range g.resolved {
+		c, ok := grd.(RouteChecker)
+		if !ok {
+			continue
+		}
+
+		err := c.CheckRoute(route)
+		if err != nil {
+			return fmt.Errorf("%s: guard (%T): %w", g.kind, grd, err)
+		}
+	}
+	return nil
+}
+
+func (g *combinedGuard) allowAll(gCtx GuardContext) (bool, error) {
+	for _, grd := range g.resolved {
+		allowed, err := grd.Allow(gCtx)
+		if err != nil {
+			return false, fmt.Errorf("guard (%T): %w", grd, err)
+		}
+		if !allowed {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (g *combinedGuard) allowAny(gCtx GuardContext) (bool, error) {
+	var errs []error
+	for _, grd := range g.resolved {
+		allowed, err := grd.Allow(gCtx)
+		if (allowed) && (err == nil) {
+			return true, nil
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("guard (%T): %w", grd, err))
+		}
+	}
+	return false, errors.Join(errs...)
+}
+
+func (g *combinedGuard) allowNot(gCtx GuardContext) (bool, error) {
+	allowed, err := g.allowAll(gCtx)
+
+	var httpErr *HTTPError
+	if (err != nil) && (!errors.As(err, &httpErr)) {
+		return false, err
+	}
+
+	return !allowed, nil
+}
+
+// resolveGuard returns the guard, resolving it with the dependencies from the scope if it
+// is a guard constructor or combines guard constructors.
+func resolveGuard(s scope, item GuardConstructor) (Guard, error) {
+	if grd, ok := item.(Guard); ok {
+		if r, ok := grd.(guardResolver); ok {
+			return r.resolveGuard(s)
+		}
+		return grd, nil
+	}
+
+	results, err := callConstructor(s, item)
+	if err != nil {
+		return nil, fmt.Errorf("error building guard (%T): %w", item, err)
+	}
+	if len(results) != 1 {
+		return nil, fmt.Errorf("guard constructor (%T) must return a single guard", item)
+	}
+
+	grd, ok := results[0].Interface().(Guard)
+	if !ok {
+		return nil, fmt.Errorf("guard constructor (%T) returned a non-guard value (%s)", item, results[0].Type())
+	}
+	if r, ok := grd.(guardResolver); ok {
+		return r.resolveGuard(s)
+	}
+
+	return grd, nil
+}
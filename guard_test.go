@@ -0,0 +1,293 @@
+package vara
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// guardLog records the names of the guards run for a request, in order.
+type guardLog struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (l *guardLog) guard(name string) Guard {
+	return &loggingGuard{name: name, log: l}
+}
+
+func (l *guardLog) take() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	names := l.names
+	l.names = nil
+	return names
+}
+
+// loggingGuard allows every request, recording its name in the log.
+type loggingGuard struct {
+	name string
+	log  *guardLog
+}
+
+func (g *loggingGuard) Allow(GuardContext) (bool, error) {
+	g.log.mu.Lock()
+	defer g.log.mu.Unlock()
+
+	g.log.names = append(g.log.names, g.name)
+	return true, nil
+}
+
+type testController struct {
+	pattern  string
+	guards   []Guard
+	metadata Metadata
+	cors     *CORSConfig
+	routes   []*RouteConfig
+}
+
+func (c *testController) Config() *ControllerConfig {
+	return &ControllerConfig{
+		Pattern:      c.pattern,
+		Guards:       c.guards,
+		Metadata:     c.metadata,
+		CORS:         c.cors,
+		RouteConfigs: c.routes,
+	}
+}
+
+type testModule struct {
+	imports     []Module
+	guards      []Guard
+	cors        *CORSConfig
+	providers   []ProviderConstructor
+	controllers []*testController
+}
+
+func (m *testModule) Config() *ModuleConfig {
+	var ctors []ControllerConstructor
+	for _, c := range m.controllers {
+		ctors = append(ctors, func() *testController { return c })
+	}
+
+	return &ModuleConfig{
+		Imports:                m.imports,
+		Guards:                 m.guards,
+		CORS:                   m.cors,
+		ProviderConstructors:   m.providers,
+		ControllerConstructors: ctors,
+	}
+}
+
+// testRoute returns the config of a GET route with the guards.
+func testRoute(pattern string, guards ...Guard) *RouteConfig {
+	return &RouteConfig{
+		Method:  http.MethodGet,
+		Pattern: pattern,
+		Guards:  guards,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	}
+}
+
+// guardsRun returns the names of the guards run for a GET request to the path.
+func guardsRun(t *testing.T, app *App, log *guardLog, path string) []string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	app.httpServer.mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s: status = %d, want %d", path, rec.Code, http.StatusOK)
+	}
+	return log.take()
+}
+
+func TestSharedModuleGuards(t *testing.T) {
+	tests := []struct {
+		name  string
+		order []string
+		want  []string
+	}{
+		{"a imported first", []string{"a", "b"}, []string{"root", "a", "b"}},
+		{"b imported first", []string{"b", "a"}, []string{"root", "b", "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &guardLog{}
+			shared := &testModule{
+				controllers: []*testController{{pattern: "/shared", routes: []*RouteConfig{testRoute("/")}}},
+			}
+
+			importers := map[string]*testModule{
+				"a": {imports: []Module{shared}, guards: []Guard{log.guard("a")}},
+				"b": {imports: []Module{shared}, guards: []Guard{log.guard("b")}},
+			}
+
+			root := &testModule{guards: []Guard{log.guard("root")}}
+			for _, name := range tt.order {
+				root.imports = append(root.imports, importers[name])
+			}
+
+			app, err := New(root)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			got := guardsRun(t, app, log, "/shared/")
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("guards run = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteGuardChain(t *testing.T) {
+	log := &guardLog{}
+
+	child := &testModule{
+		guards: []Guard{log.guard("child")},
+		controllers: []*testController{
+			{
+				pattern: "/a",
+				guards:  []Guard{log.guard("ctrl-a")},
+				routes: []*RouteConfig{
+					testRoute("/x", log.guard("route-x")),
+					testRoute("/y"),
+					{
+						Method:   http.MethodGet,
+						Pattern:  "/public",
+						Guards:   []Guard{log.guard("route-public")},
+						Metadata: Metadata{Public()},
+						Handler:  http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+					},
+				},
+			},
+			{
+				pattern: "/b",
+				guards:  []Guard{log.guard("ctrl-b")},
+				routes:  []*RouteConfig{testRoute("/z", log.guard("route-z"))},
+			},
+		},
+	}
+
+	root := &testModule{
+		imports:   []Module{child},
+		guards:    []Guard{log.guard("root")},
+		providers: []ProviderConstructor{GlobalGuard(func() Guard { return log.guard("provided") })},
+		controllers: []*testController{
+			{pattern: "/root", routes: []*RouteConfig{testRoute("/")}},
+		},
+	}
+
+	app, err := New(root, WithGlobalGuards(log.guard("global")))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/a/x", []string{"global", "provided", "root", "child", "ctrl-a", "route-x"}},
+		{"/a/y", []string{"global", "provided", "root", "child", "ctrl-a"}},
+		{"/a/public", []string{"ctrl-a", "route-public"}},
+		{"/b/z", []string{"global", "provided", "root", "child", "ctrl-b", "route-z"}},
+		{"/root/", []string{"global", "provided", "root"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got := guardsRun(t, app, log, tt.path)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("guards run = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlobalGuardsAddedAfterNew(t *testing.T) {
+	log := &guardLog{}
+	root := &testModule{
+		guards:      []Guard{log.guard("root")},
+		controllers: []*testController{{pattern: "/root", routes: []*RouteConfig{testRoute("/")}}},
+	}
+
+	app, err := New(root, WithGlobalGuards(log.guard("option")))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	err = app.UseGlobalGuards(log.guard("use"))
+	if err != nil {
+		t.Fatalf("UseGlobalGuards() error = %v", err)
+	}
+
+	want := []string{"option", "use", "root"}
+	for i := 0; i < 2; i++ {
+		got := guardsRun(t, app, log, "/root/")
+		if !slices.Equal(got, want) {
+			t.Errorf("request %d: guards run = %v, want %v", i, got, want)
+		}
+	}
+}
+
+// checkingGuard allows every request, and rejects the routes with the path when they are registered.
+type checkingGuard struct {
+	path string
+}
+
+func (g *checkingGuard) Allow(GuardContext) (bool, error) { return true, nil }
+
+func (g *checkingGuard) CheckRoute(route RouteInfo) error {
+	if route.Path == g.path {
+		return errors.New("rejected")
+	}
+	return nil
+}
+
+func TestRouteChecker(t *testing.T) {
+	newRoot := func(guards ...Guard) *testModule {
+		return &testModule{
+			controllers: []*testController{
+				{pattern: "/a", routes: []*RouteConfig{testRoute("/x", guards...), testRoute("/y")}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		root    *testModule
+		opts    []Option
+		wantErr bool
+	}{
+		{"route guard", newRoot(&checkingGuard{path: "/a/x"}), nil, true},
+		{"combined route guard", newRoot(AllOf(&checkingGuard{path: "/a/x"})), nil, true},
+		{"global guard", newRoot(), []Option{WithGlobalGuards(&checkingGuard{path: "/a/y"})}, true},
+		{"other route", newRoot(&checkingGuard{path: "/a/y"}), nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.root, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("guard added after New", func(t *testing.T) {
+		app, err := New(newRoot())
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+
+		err = app.UseGlobalGuards(&checkingGuard{path: "/a/y"})
+		if err == nil {
+			t.Error("UseGlobalGuards() error = nil, want an error")
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package vara
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// HTTPError is an error with the HTTP status and message of the response it results in.
+//
+// Guards return an HTTPError to choose how a request is rejected:
+//
+//	func (g *AuthGuard) Allow(gCtx vara.GuardContext) (bool, error) {
+//		if gCtx.Http.R.Header.Get("Authorization") == "" {
+//			return false, vara.ErrUnauthorized.WithHeader("WWW-Authenticate", `Bearer realm="api"`)
+//		}
+//		...
+//	}
+type HTTPError struct {
+	// Status is the HTTP status code of the response.
+	Status int
+
+	// Message is the message sent to the client.
+	Message string
+
+	// Header holds headers set on the response, e.g. WWW-Authenticate or Retry-After.
+	Header http.Header
+
+	// Err is the underlying cause of the error. It is not sent to the client.
+	Err error
+}
+
+// common HTTP errors, with the status text as their message
+var (
+	ErrBadRequest          = NewHTTPError(http.StatusBadRequest, "")
+	ErrUnauthorized        = NewHTTPError(http.StatusUnauthorized, "")
+	ErrForbidden           = NewHTTPError(http.StatusForbidden, "")
+	ErrNotFound            = NewHTTPError(http.StatusNotFound, "")
+	ErrConflict            = NewHTTPError(http.StatusConflict, "")
+	ErrTooManyRequests     = NewHTTPError(http.StatusTooManyRequests, "")
+	ErrInternalServerError = NewHTTPError(http.StatusInternalServerError, "")
+	ErrServiceUnavailable  = NewHTTPError(http.StatusServiceUnavailable, "")
+)
+
+// NewHTTPError returns an HTTP error with the status and message.
+// The status text is used as the message if msg is empty.
+func NewHTTPError(status int, msg string) *HTTPError {
+	if msg == "" {
+		msg = http.StatusText(status)
+	}
+
+	return &HTTPError{
+		Status:  status,
+		Message: msg,
+	}
+}
+
+func (e *HTTPError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%d %s: %s", e.Status, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%d %s", e.Status, e.Message)
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an HTTP error with the same status, so that
+// errors.Is(err, vara.ErrUnauthorized) matches every 401 error.
+func (e *HTTPError) Is(target error) bool {
+	t, ok := target.(*HTTPError)
+	return ok && (t.Status == e.Status)
+}
+
+// WithMessage returns a copy of the error with the message.
+func (e *HTTPError) WithMessage(msg string) *HTTPError {
+	c := e.clone()
+	c.Message = msg
+	return c
+}
+
+// WithHeader returns a copy of the error that sets the header on the response.
+func (e *HTTPError) WithHeader(key, value string) *HTTPError {
+	c := e.clone()
+	c.Header.Set(key, value)
+	return c
+}
+
+// Wrap returns a copy of the error caused by err.
+func (e *HTTPError) Wrap(err error) *HTTPError {
+	c := e.clone()
+	c.Err = err
+	return c
+}
+
+func (e *HTTPError) clone() *HTTPError {
+	c := *e
+	c.Header = e.Header.Clone()
+	if c.Header == nil {
+		c.Header = http.Header{}
+	}
+	return &c
+}
+
+// getRejection returns the HTTP error a request is rejected with when a guard denies it
+// with err. Errors that are not an [HTTPError] result in an internal server error.
+func getRejection(err error) *HTTPError {
+	if err == nil {
+		return ErrForbidden
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+
+	return ErrInternalServerError.Wrap(err)
+}
+
+// RejectionRenderer writes the response of requests rejected by guards.
+type RejectionRenderer interface {
+	// RenderRejection writes the response for the HTTP error the request was rejected with.
+	// The error's headers are already set on the response.
+	RenderRejection(w http.ResponseWriter, r *http.Request, err *HTTPError)
+}
+
+// RejectionRendererConstructor is a function that takes any number of dependencies
+// as its parameters and returns a value that meets the `RejectionRenderer` interface
+// and may optionally return an error to indicate that it failed to build the value.
+//
+// Any arguments that the constructor has are treated as its dependencies. The dependencies are instantiated
+// in an unspecified order along with any dependencies that they might have.
+type RejectionRendererConstructor constructor
+
+// rejectionRendererName is the name the application's rejection renderer is provided under in the root scope.
+const rejectionRendererName = "vara.rejection_renderer"
+
+// GlobalRejectionRenderer annotates a rejection renderer constructor so the renderer writes the
+// responses of requests rejected by any guard of the application. It can be listed in
+// ProviderConstructors of any module. Rejections are written as plain text by default.
+func GlobalRejectionRenderer(ctor RejectionRendererConstructor) ProviderConstructor {
+	return Provide(ctor, Name(rejectionRendererName), As(new(RejectionRenderer)), Global())
+}
+
+// textRejectionRenderer writes rejections as plain text.
+type textRejectionRenderer struct{}
+
+func (textRejectionRenderer) RenderRejection(w http.ResponseWriter, _ *http.Request, err *HTTPError) {
+	http.Error(w, err.Message, err.Status)
+}
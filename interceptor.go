@@ -0,0 +1,52 @@
+package vara
+
+import "net/http"
+
+// Interceptor wraps the handling of requests on routes, e.g. to instrument, log or
+// transform them. Interceptors run around a route's guards and handler.
+type Interceptor interface {
+	// Intercept returns a handler that wraps next for the given route. It is called
+	// once for each route when the route is registered.
+	Intercept(route RouteInfo, next http.Handler) http.Handler
+}
+
+// InterceptorConstructor is a function that takes any number of dependencies
+// as its parameters and returns a value that meets the `Interceptor` interface
+// and may optionally return an error to indicate that it failed to build the value.
+//
+// Any arguments that the constructor has are treated as its dependencies. The dependencies are instantiated
+// in an unspecified order along with any dependencies that they might have.
+type InterceptorConstructor constructor
+
+// RouteInfo describes a route registered by a controller.
+type RouteInfo struct {
+	// Method is the HTTP method of the route.
+	Method string
+
+	// Path is the path template of the route, including the controller's pattern.
+	Path string
+
+	// RouteConfig contains metadata and configuration specific to the route.
+	RouteConfig RouteConfig
+
+	// ControllerConfig contains metadata and configuration for the controller.
+	ControllerConfig ControllerConfig
+
+	// ModuleConfig contains metadata and configuration for the controller's module.
+	ModuleConfig ModuleConfig
+
+	// Module is the name of the controller's module, e.g. "auth" for an *auth.Module.
+	Module string
+}
+
+// GlobalInterceptor annotates an interceptor constructor so the interceptor is applied
+// to every route of the application. It can be listed in ProviderConstructors of any module.
+//
+// Example:
+//
+//	ProviderConstructors: []vara.ProviderConstructor{
+//		vara.GlobalInterceptor(newMetricsInterceptor),
+//	}
+func GlobalInterceptor(ctor InterceptorConstructor) ProviderConstructor {
+	return Provide(ctor, Group(groupInterceptors.String()), As(new(Interceptor)), Global())
+}
@@ -0,0 +1,231 @@
+package vara
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/dig"
+)
+
+// Lazy defers resolving a constructor dependency until [Lazy.Get] is first called,
+// instead of when the constructor is called.
+//
+// Because a lazy dependency is not part of the dependency graph at construction time,
+// it can be used to break circular dependencies between providers, e.g. an auth service
+// depending on a user service that itself needs the auth service for its event listeners.
+//
+// Example:
+//
+//	func NewUserService(auth vara.Lazy[*auth.Service]) *UserService {
+//		return &UserService{auth: auth}
+//	}
+//
+//	func (s *UserService) onSignup(e event.Event) error {
+//		auth, err := s.auth.Get()
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// Get may be called from within the constructor the dependency is injected into, e.g. to
+// resolve a dependency that itself lazily depends on the constructor's module, but not from
+// within a constructor of the dependency it resolves, as the dependency would not have been
+// built yet.
+type Lazy[T any] struct {
+	dig.In `ignore-unexported:"true"`
+
+	// injector resolves the dependency from the scope the dependent constructor was provided to.
+	// It is bound once the constructor is called, see [lazyInjector.bind].
+	injector *lazyInjector
+}
+
+// ErrLazyNotInjected indicates that a [Lazy] dependency was not created by the Vara injector.
+var ErrLazyNotInjected = errors.New("lazy dependency was not injected")
+
+// Get resolves and returns the dependency. Provider constructors are only called once,
+// so subsequent calls return the same instance.
+func (l Lazy[T]) Get() (T, error) {
+	var value T
+
+	if l.injector == nil {
+		return value, ErrLazyNotInjected
+	}
+
+	err := l.injector.invoke(
+		func(v T) {
+			value = v
+		},
+	)
+
+	return value, err
+}
+
+// MustGet is like [Lazy.Get] but panics if the dependency could not be resolved.
+func (l Lazy[T]) MustGet() T {
+	value, err := l.Get()
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func (l *Lazy[T]) setInjector(i *lazyInjector) {
+	l.injector = i
+}
+
+// lazyDependency is implemented by pointers to [Lazy] dependencies.
+type lazyDependency interface {
+	setInjector(*lazyInjector)
+}
+
+// reflected types of the injector and lazy dependencies
+var (
+	injectorType       = reflect.TypeOf((*injector)(nil))
+	lazyDependencyType = reflect.TypeOf((*lazyDependency)(nil)).Elem()
+)
+
+// injector resolves dependencies from a DI scope of an application on demand.
+type injector struct {
+	// mu serializes calls into the application's container, which is not safe for
+	// concurrent use. It is held while the application is built, and by every call
+	// into the container afterwards.
+	mu    *sync.Mutex
+	scope scope
+}
+
+func newInjector(mu *sync.Mutex, s scope) *injector {
+	return &injector{
+		mu:    mu,
+		scope: s,
+	}
+}
+
+// invoke calls fn with its parameters resolved from the injector's scope, holding the container's lock.
+func (i *injector) invoke(fn any) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.scope.Invoke(fn)
+}
+
+// lazyInjector resolves the [Lazy] dependencies injected into a call to a constructor.
+type lazyInjector struct {
+	*injector
+
+	// calling reports if the container is calling the constructor, and so holds its lock.
+	// Dependencies resolved by the constructor while it is called are resolved without
+	// taking the lock again.
+	calling atomic.Bool
+}
+
+func newLazyInjector(i *injector, calling bool) *lazyInjector {
+	li := &lazyInjector{injector: i}
+	li.calling.Store(calling)
+	return li
+}
+
+// invoke calls fn with its parameters resolved from the injector's scope.
+func (i *lazyInjector) invoke(fn any) error {
+	if i.calling.Load() {
+		return i.scope.Invoke(fn)
+	}
+	return i.injector.invoke(fn)
+}
+
+// call calls fn with args, binding the lazy dependencies in them to the injector.
+func (i *lazyInjector) call(fn reflect.Value, args []reflect.Value) []reflect.Value {
+	defer i.calling.Store(false)
+
+	for j, arg := range args {
+		args[j] = i.bind(arg)
+	}
+	return callFunc(fn, args)
+}
+
+// bind returns v bound to the injector if it is a [Lazy] dependency, or with the lazy
+// dependencies in it bound if it is a parameter struct embedding [In].
+func (i *lazyInjector) bind(v reflect.Value) reflect.Value {
+	switch {
+	case reflect.PointerTo(v.Type()).Implements(lazyDependencyType):
+		bound := reflect.New(v.Type())
+		bound.Elem().Set(v)
+		bound.Interface().(lazyDependency).setInjector(i)
+		return bound.Elem()
+
+	case (v.Kind() == reflect.Struct) && (dig.IsIn(v.Type())):
+		bound := reflect.New(v.Type()).Elem()
+		bound.Set(v)
+		for j := 0; j < v.NumField(); j++ {
+			if bound.Field(j).CanSet() {
+				bound.Field(j).Set(i.bind(v.Field(j)))
+			}
+		}
+		return bound
+
+	default:
+		return v
+	}
+}
+
+// hasLazyDependency reports if a constructor of the type has a [Lazy] dependency,
+// including in parameter structs embedding [In].
+func hasLazyDependency(fnType reflect.Type) bool {
+	var has func(t reflect.Type) bool
+	has = func(t reflect.Type) bool {
+		if reflect.PointerTo(t).Implements(lazyDependencyType) {
+			return true
+		}
+		if (t.Kind() != reflect.Struct) || (!dig.IsIn(t)) {
+			return false
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); (f.PkgPath == "") && has(f.Type) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := 0; i < fnType.NumIn(); i++ {
+		if has(fnType.In(i)) {
+			return true
+		}
+	}
+	return false
+}
+
+// withInjector returns a function with the parameters of fn's type and the results out, that
+// also depends on the [injector] of the scope it is called in. The injector is passed to call
+// along with the function's arguments.
+func withInjector(fnType reflect.Type, out []reflect.Type, call func(i *injector, args []reflect.Value) []reflect.Value) reflect.Value {
+	in := []reflect.Type{injectorType}
+	for i := 0; i < fnType.NumIn(); i++ {
+		in = append(in, fnType.In(i))
+	}
+
+	return reflect.MakeFunc(
+		reflect.FuncOf(in, out, fnType.IsVariadic()),
+		func(args []reflect.Value) []reflect.Value {
+			return call(args[0].Interface().(*injector), args[1:])
+		},
+	)
+}
+
+// bindLazy returns a constructor like ctor, that binds the [Lazy] dependencies it is called
+// with to the scope it is provided to, and the options reporting ctor's location in errors.
+// ctor is returned as is if it is not a function with lazy dependencies.
+func bindLazy(ctor constructor) (constructor, []dig.ProvideOption) {
+	fn := reflect.ValueOf(ctor)
+	if (fn.Kind() != reflect.Func) || (!hasLazyDependency(fn.Type())) {
+		return ctor, nil
+	}
+
+	wrapped := withInjector(fn.Type(), getResultTypes(fn.Type()), func(i *injector, args []reflect.Value) []reflect.Value {
+		return newLazyInjector(i, true).call(fn, args)
+	})
+
+	return wrapped.Interface(), []dig.ProvideOption{dig.LocationForPC(fn.Pointer())}
+}
@@ -0,0 +1,215 @@
+package vara
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ctorModule provides the providers and controllers built by its constructors.
+type ctorModule struct {
+	providers   []ProviderConstructor
+	controllers []ControllerConstructor
+}
+
+func (m *ctorModule) Config() *ModuleConfig {
+	return &ModuleConfig{
+		ProviderConstructors:   m.providers,
+		ControllerConstructors: m.controllers,
+	}
+}
+
+type (
+	lazyA struct{ b *lazyB }
+	lazyB struct{ c *lazyC }
+	lazyC struct{}
+)
+
+// newLazyChain returns the constructors of A, B and C, each resolving the next lazily while it is built.
+func newLazyChain() []ProviderConstructor {
+	return []ProviderConstructor{
+		func(b Lazy[*lazyB]) (*lazyA, error) {
+			v, err := b.Get()
+			return &lazyA{b: v}, err
+		},
+		func(c Lazy[*lazyC]) (*lazyB, error) {
+			v, err := c.Get()
+			return &lazyB{c: v}, err
+		},
+		func() *lazyC { return &lazyC{} },
+	}
+}
+
+func TestLazy(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"lazy build", nil},
+		{"concurrent build", []Option{WithConcurrentBuild()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var a Lazy[*lazyA]
+			root := &ctorModule{
+				providers: newLazyChain(),
+				controllers: []ControllerConstructor{
+					func(l Lazy[*lazyA]) *testController {
+						a = l
+						return &testController{}
+					},
+				},
+			}
+
+			_, err := New(root, tt.opts...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			var (
+				wg  sync.WaitGroup
+				got = make([]*lazyA, 10)
+			)
+			for i := range got {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					got[i] = a.MustGet()
+				}()
+			}
+			wg.Wait()
+
+			for i, v := range got {
+				if (v != got[0]) || (v.b == nil) || (v.b.c == nil) {
+					t.Fatalf("Get() #%d = %+v, want the same fully built value", i, v)
+				}
+			}
+		})
+	}
+}
+
+func TestLazyResolvedWhileBuilt(t *testing.T) {
+	var got *lazyA
+	root := &ctorModule{
+		providers: newLazyChain(),
+		controllers: []ControllerConstructor{
+			func(a Lazy[*lazyA]) (*testController, error) {
+				var err error
+				got, err = a.Get()
+				return &testController{}, err
+			},
+		},
+	}
+
+	_, err := New(root)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if (got == nil) || (got.b == nil) || (got.b.c == nil) {
+		t.Errorf("resolved %+v, want a fully built value", got)
+	}
+}
+
+func TestLazyNotInjected(t *testing.T) {
+	var l Lazy[*lazyA]
+
+	_, err := l.Get()
+	if !errors.Is(err, ErrLazyNotInjected) {
+		t.Errorf("Get() error = %v, want %v", err, ErrLazyNotInjected)
+	}
+}
+
+// TestLazyAppsBuildConcurrently checks that the container locks of applications are
+// separate, as each application's build waits for the other's while holding its lock.
+func TestLazyAppsBuildConcurrently(t *testing.T) {
+	type (
+		waiter  struct{}
+		builder struct{}
+	)
+
+	newApp := func(ready chan<- struct{}, other <-chan struct{}) func() error {
+		root := &ctorModule{
+			providers: []ProviderConstructor{
+				func() *waiter {
+					close(ready)
+					<-other
+					return &waiter{}
+				},
+				func(w Lazy[*waiter]) (*builder, error) {
+					_, err := w.Get()
+					return &builder{}, err
+				},
+			},
+			controllers: []ControllerConstructor{
+				func(*builder) *testController { return &testController{} },
+			},
+		}
+		return func() error {
+			_, err := New(root)
+			return err
+		}
+	}
+
+	readyA, readyB := make(chan struct{}), make(chan struct{})
+	apps := []func() error{newApp(readyA, readyB), newApp(readyB, readyA)}
+
+	errs := make(chan error, len(apps))
+	for _, build := range apps {
+		go func() { errs <- build() }()
+	}
+
+	for range apps {
+		select {
+		case err := <-errs:
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("applications did not build concurrently")
+		}
+	}
+}
+
+func TestOptional(t *testing.T) {
+	type (
+		provided struct{ name string }
+		missing  struct{}
+	)
+
+	var (
+		gotProvided Optional[*provided]
+		gotMissing  Optional[*missing]
+		gotZero     Optional[int]
+	)
+
+	root := &ctorModule{
+		providers: []ProviderConstructor{
+			func() *provided { return &provided{name: "provided"} },
+			func() int { return 0 },
+		},
+		controllers: []ControllerConstructor{
+			func(p Optional[*provided], m Optional[*missing], z Optional[int]) *testController {
+				gotProvided, gotMissing, gotZero = p, m, z
+				return &testController{}
+			},
+		},
+	}
+
+	_, err := New(root)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if v, ok := gotProvided.Get(); !ok || (v.name != "provided") {
+		t.Errorf("provided Get() = %v, %v, want the provided value", v, ok)
+	}
+	if v, ok := gotMissing.Get(); ok || (v != nil) {
+		t.Errorf("missing Get() = %v, %v, want nil, false", v, ok)
+	}
+	// a provided zero value can't be told apart from a missing one
+	if v, ok := gotZero.Get(); ok || (v != 0) {
+		t.Errorf("zero Get() = %v, %v, want 0, false", v, ok)
+	}
+}
@@ -2,7 +2,8 @@ package vara
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"reflect"
 	"sync"
 )
 
@@ -33,63 +34,306 @@ import (
 //
 //		return us
 //	}
+//
+// Hooks are ordered by the dependencies between the providers that appended them: the hooks
+// of a provider start after, and stop before, the hooks of the providers it depends on. Hooks
+// whose providers don't depend on each other start and stop concurrently, while the hooks of a
+// single provider run in the order they were appended. Hooks appended outside of provider
+// constructors, e.g. by controllers, start last and stop first.
 type Lifecycle struct {
-	mutex sync.Mutex
-	hooks []LifecycleHook
+	mutex       sync.Mutex
+	hooks       []lifecycleEntry
+	started     [][]lifecycleGroup
+	parallelism int
+
+	// root is the application's lifecycle that a provider's lifecycle appends to.
+	root *Lifecycle
+
+	// owner is the provider constructor that hooks appended to the lifecycle belong to.
+	owner *ctorCall
+}
+
+// lifecycleType is the reflected type of a lifecycle injected into constructors.
+var lifecycleType = reflect.TypeOf((*Lifecycle)(nil))
+
+// lifecycleEntry is a hook along with the provider constructor that appended it.
+type lifecycleEntry struct {
+	hook  LifecycleHook
+	owner *ctorCall
 }
 
+// lifecycleGroup is the hooks appended by a single provider constructor.
+type lifecycleGroup []LifecycleHook
+
 // newLifecycle creates a new Lifecycle instance.
 func newLifecycle() *Lifecycle {
 	return &Lifecycle{
-		hooks: []LifecycleHook{},
+		hooks: []lifecycleEntry{},
 	}
 }
 
-// Stop calls the OnStop functions of all registered hooks in reverse order.
+// withOwner returns a lifecycle that appends hooks to l on behalf of the provider constructor.
+func (l *Lifecycle) withOwner(owner *ctorCall) *Lifecycle {
+	root := l
+	if l.root != nil {
+		root = l.root
+	}
+
+	return &Lifecycle{
+		root:  root,
+		owner: owner,
+	}
+}
+
+// setParallelism limits how many hooks may run concurrently. No limit is applied if n is zero.
+func (l *Lifecycle) setParallelism(n int) {
+	l.mutex.Lock()
+	l.parallelism = n
+	l.mutex.Unlock()
+}
+
+// Stop calls the OnStop functions of all started hooks in reverse order.
+//
+// Every hook is stopped even if some fail, and the failures are joined into the returned error.
 func (l *Lifecycle) stop(ctx context.Context) error {
+	l.mutex.Lock()
+	started := l.started
+	l.started = nil
+	l.mutex.Unlock()
+
 	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		errs = append(errs, l.runWave(ctx, started[i], LifecyclePhaseStop)...)
+	}
 
-	for i := len(l.hooks) - 1; i >= 0; i-- {
-		fn := l.hooks[i].OnStop
-		if fn != nil {
-			err := fn(ctx)
-			if err != nil {
-				errs = append(errs, err)
-			}
-		}
+	return errors.Join(errs...)
+}
+
+// preStop calls the OnPreStop functions of all started hooks concurrently.
+func (l *Lifecycle) preStop(ctx context.Context) error {
+	l.mutex.Lock()
+	var wave []lifecycleGroup
+	for _, started := range l.started {
+		wave = append(wave, started...)
 	}
+	l.mutex.Unlock()
+
+	return errors.Join(l.runWave(ctx, wave, LifecyclePhasePreStop)...)
+}
 
-	if len(errs) > 0 {
-		return fmt.Errorf("lifecycle stop hooks failed: %v", errs)
+// Start calls the OnStart functions of all registered hooks in order.
+//
+// Starting stops at the first wave of hooks that fails, and the hooks started before
+// are rolled back by calling their OnStop functions in reverse order.
+func (l *Lifecycle) start(ctx context.Context) error {
+	for _, wave := range l.getWaves() {
+		errs := l.runWave(ctx, wave, LifecyclePhaseStart)
+		if len(errs) > 0 {
+			// roll back the hooks started so far
+			return errors.Join(append(errs, l.stop(ctx))...)
+		}
 	}
 
 	return nil
 }
 
-// Start calls the OnStart functions of all registered hooks.
-func (l *Lifecycle) start(ctx context.Context) error {
-	var errs []error
+// runWave runs the hook groups concurrently, and the hooks in each group one after the other;
+// in append order when starting and in reverse order when stopping.
+//
+// When starting, a group stops at its first failing hook, and the hooks that did start are
+// recorded so they can be stopped later.
+func (l *Lifecycle) runWave(ctx context.Context, wave []lifecycleGroup, phase LifecyclePhase) []error {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		errs    []error
+		started []lifecycleGroup
+		sem     chan struct{}
+	)
+
+	l.mutex.Lock()
+	if l.parallelism > 0 {
+		sem = make(chan struct{}, l.parallelism)
+	}
+	l.mutex.Unlock()
+
+	for _, group := range wave {
+		wg.Add(1)
+		go func(group lifecycleGroup) {
+			defer wg.Done()
 
-	for _, hook := range l.hooks {
-		fn := hook.OnStart
-		if fn != nil {
-			err := fn(ctx)
-			if err != nil {
-				errs = append(errs, err)
+			var ran lifecycleGroup
+			for i := range group {
+				hook := group[i]
+				if phase == LifecyclePhaseStop {
+					hook = group[len(group)-1-i]
+				}
+
+				if sem != nil {
+					sem <- struct{}{}
+				}
+				err := runHook(ctx, hook, phase)
+				if sem != nil {
+					<-sem
+				}
+
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, err)
+				}
+				mu.Unlock()
+
+				if (err != nil) && (phase == LifecyclePhaseStart) {
+					break
+				}
+				ran = append(ran, hook)
 			}
+
+			mu.Lock()
+			started = append(started, ran)
+			mu.Unlock()
+		}(group)
+	}
+	wg.Wait()
+
+	if phase == LifecyclePhaseStart {
+		l.mutex.Lock()
+		l.started = append(l.started, started)
+		l.mutex.Unlock()
+	}
+
+	return errs
+}
+
+// getWaves groups the registered hooks by the provider constructor that appended them, and
+// orders the groups into waves. Each group only depends on the groups of earlier waves.
+func (l *Lifecycle) getWaves() [][]lifecycleGroup {
+	l.mutex.Lock()
+	entries := l.hooks
+	l.mutex.Unlock()
+
+	var (
+		owners []*ctorCall
+		groups = map[*ctorCall]lifecycleGroup{}
+	)
+
+	for _, e := range entries {
+		if _, ok := groups[e.owner]; !ok {
+			owners = append(owners, e.owner)
 		}
+		groups[e.owner] = append(groups[e.owner], e.hook)
 	}
 
-	if len(errs) > 0 {
-		return fmt.Errorf("lifecycle start hooks failed: %v", errs)
+	var (
+		depth     = map[*ctorCall]int{}
+		maxDepth  = 0
+		isVisited = map[*ctorCall]bool{}
+		getDepth  func(c *ctorCall) int
+	)
+
+	// getDepth returns the number of constructors with hooks
+	// on the longest dependency chain below the constructor.
+	getDepth = func(c *ctorCall) int {
+		if d, ok := depth[c]; ok {
+			return d
+		}
+		if isVisited[c] {
+			// dependency cycle
+			return 0
+		}
+		isVisited[c] = true
+
+		d := 0
+		for _, dep := range c.dependencies {
+			depDepth := getDepth(dep)
+			if _, hasHooks := groups[dep]; hasHooks {
+				depDepth++
+			}
+			d = max(d, depDepth)
+		}
+
+		depth[c] = d
+		return d
 	}
 
-	return nil
+	for _, owner := range owners {
+		if owner != nil {
+			maxDepth = max(maxDepth, getDepth(owner))
+		}
+	}
+
+	waves := make([][]lifecycleGroup, maxDepth+2)
+	for _, owner := range owners {
+		// hooks appended outside of provider constructors start last
+		d := maxDepth + 1
+		if owner != nil {
+			d = depth[owner]
+		}
+		waves[d] = append(waves[d], groups[owner])
+	}
+
+	var nonEmpty [][]lifecycleGroup
+	for _, wave := range waves {
+		if len(wave) > 0 {
+			nonEmpty = append(nonEmpty, wave)
+		}
+	}
+
+	return nonEmpty
 }
 
 // Append registers a new lifecycle hook.
 func (l *Lifecycle) Append(hooks ...LifecycleHook) {
-	l.mutex.Lock()
-	l.hooks = append(l.hooks, hooks...)
-	l.mutex.Unlock()
+	root := l
+	if l.root != nil {
+		root = l.root
+	}
+
+	root.mutex.Lock()
+	for _, hook := range hooks {
+		root.hooks = append(root.hooks, lifecycleEntry{hook: hook, owner: l.owner})
+	}
+	root.mutex.Unlock()
+}
+
+// runHook calls the hook's function for the phase, enforcing the hook's timeout.
+func runHook(ctx context.Context, hook LifecycleHook, phase LifecyclePhase) error {
+	fn := hook.OnStart
+	switch phase {
+	case LifecyclePhaseStop:
+		fn = hook.OnStop
+	case LifecyclePhasePreStop:
+		fn = hook.OnPreStop
+	}
+	if fn == nil {
+		return nil
+	}
+
+	if hook.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, hook.Timeout)
+		defer cancel()
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		return &LifecycleHookError{
+			Hook:  hook.getName(fn),
+			Phase: phase,
+			Err:   err,
+		}
+	}
+
+	return nil
 }
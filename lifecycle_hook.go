@@ -1,10 +1,30 @@
 package vara
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"time"
+)
 
 // LifecycleHook represents a lifecycle hook with functions that can be registered
 // to execute specific tasks during the application's lifecycle.
 type LifecycleHook struct {
+	// Name identifies the hook in errors. Defaults to the name of the hook's function.
+	Name string
+
+	// Timeout limits how long each of the hook's functions may run. The context passed
+	// to the functions is canceled once it elapses. No limit is applied if it is zero.
+	Timeout time.Duration
+
+	// OnPreStop defines a lifecycle hook that is triggered as soon as a graceful shutdown
+	// begins, before any hook's OnStop function is called.
+	//
+	// Use this hook to stop advertising the application as ready, e.g. to let
+	// load balancers drain traffic before resources are released.
+	OnPreStop LifecycleHookFunc
+
 	// OnStop defines a lifecycle hook that is triggered during a graceful shutdown,
 	// before the application stops accepting new requests.
 	//
@@ -19,5 +39,48 @@ type LifecycleHook struct {
 	OnStart LifecycleHookFunc
 }
 
+// getName returns the hook's name, or the name of fn if the hook is unnamed.
+func (h LifecycleHook) getName(fn LifecycleHookFunc) string {
+	if h.Name != "" {
+		return h.Name
+	}
+	if f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()); f != nil {
+		return f.Name()
+	}
+	return "unnamed"
+}
+
 // LifecycleHookFunc represents a [lifecycleHook] function
 type LifecycleHookFunc func(context.Context) error
+
+// LifecyclePhase is the phase of the application's lifecycle that a hook function runs in.
+type LifecyclePhase string
+
+// recognized lifecycle phases
+const (
+	LifecyclePhaseStop    = LifecyclePhase("stop")
+	LifecyclePhaseStart   = LifecyclePhase("start")
+	LifecyclePhasePreStop = LifecyclePhase("pre-stop")
+)
+
+// LifecycleHookError is returned when a lifecycle hook fails, identifying the hook and phase.
+type LifecycleHookError struct {
+	// Hook is the name of the hook that failed.
+	Hook string
+
+	// Phase is the lifecycle phase the hook failed in.
+	Phase LifecyclePhase
+
+	// Err is the error returned by the hook's function.
+	Err error
+}
+
+// Error makes LifecycleHookError meets the error interface
+func (e *LifecycleHookError) Error() string {
+	return fmt.Sprintf("lifecycle hook (%s) failed to %s: %v", e.Hook, e.Phase, e.Err)
+}
+
+// Unwrap returns the error returned by the hook's function.
+func (e *LifecycleHookError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,374 @@
+package vara
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// hookLog records the lifecycle functions run by hooks, in order.
+type hookLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *hookLog) record(event string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.events = append(l.events, event)
+}
+
+func (l *hookLog) take() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	events := l.events
+	l.events = nil
+	return events
+}
+
+// hook returns a hook recording its functions in the log, failing to start if startErr is set.
+func (l *hookLog) hook(name string, startErr error) LifecycleHook {
+	return LifecycleHook{
+		Name: name,
+		OnStart: func(context.Context) error {
+			l.record("start " + name)
+			return startErr
+		},
+		OnStop: func(context.Context) error {
+			l.record("stop " + name)
+			return nil
+		},
+	}
+}
+
+func TestLifecycleOrder(t *testing.T) {
+	var (
+		ctx = context.Background()
+		log = &hookLog{}
+		lc  = newLifecycle()
+	)
+
+	lc.Append(log.hook("a", nil), log.hook("b", nil))
+	lc.Append(log.hook("c", nil))
+
+	err := lc.start(ctx)
+	if err != nil {
+		t.Fatalf("start() error = %v", err)
+	}
+	if got, want := log.take(), []string{"start a", "start b", "start c"}; !slices.Equal(got, want) {
+		t.Errorf("started hooks = %v, want %v", got, want)
+	}
+
+	err = lc.stop(ctx)
+	if err != nil {
+		t.Fatalf("stop() error = %v", err)
+	}
+	if got, want := log.take(), []string{"stop c", "stop b", "stop a"}; !slices.Equal(got, want) {
+		t.Errorf("stopped hooks = %v, want %v", got, want)
+	}
+
+	// hooks are only stopped once
+	err = lc.stop(ctx)
+	if got := log.take(); (err != nil) || (len(got) > 0) {
+		t.Errorf("second stop() = %v, %v, want no hook stopped", got, err)
+	}
+}
+
+func TestLifecycleRollback(t *testing.T) {
+	var (
+		log     = &hookLog{}
+		lc      = newLifecycle()
+		errBoom = errors.New("boom")
+	)
+
+	lc.Append(log.hook("a", nil), log.hook("b", nil), log.hook("c", errBoom), log.hook("d", nil))
+
+	err := lc.start(context.Background())
+
+	var hookErr *LifecycleHookError
+	if !errors.As(err, &hookErr) || (hookErr.Hook != "c") || (hookErr.Phase != LifecyclePhaseStart) || !errors.Is(err, errBoom) {
+		t.Fatalf("start() error = %v, want the start error of hook c", err)
+	}
+
+	// the failing hook and the hooks after it never started, so they are not stopped
+	want := []string{"start a", "start b", "start c", "stop b", "stop a"}
+	if got := log.take(); !slices.Equal(got, want) {
+		t.Errorf("hooks run = %v, want %v", got, want)
+	}
+}
+
+func TestLifecycleHookTimeout(t *testing.T) {
+	lc := newLifecycle()
+	lc.Append(LifecycleHook{
+		Name:    "slow",
+		Timeout: 10 * time.Millisecond,
+		OnStart: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+
+	err := lc.start(context.Background())
+
+	var hookErr *LifecycleHookError
+	if !errors.As(err, &hookErr) || (hookErr.Hook != "slow") || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("start() error = %v, want hook slow to time out", err)
+	}
+
+	// hooks ignoring their context are abandoned once their timeout elapses
+	lc = newLifecycle()
+	lc.Append(LifecycleHook{
+		Name:    "unresponsive",
+		Timeout: 10 * time.Millisecond,
+		OnStop: func(context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		},
+	})
+	_ = lc.start(context.Background())
+
+	start := time.Now()
+	err = lc.stop(context.Background())
+	if !errors.As(err, &hookErr) || (hookErr.Phase != LifecyclePhaseStop) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("stop() error = %v, want hook unresponsive to time out", err)
+	}
+	if elapsed := time.Since(start); elapsed > (500 * time.Millisecond) {
+		t.Errorf("stop() took %s, want it to return once the hook's timeout elapsed", elapsed)
+	}
+}
+
+type (
+	lcDatabase   struct{}
+	lcRepository struct{}
+	lcService    struct{}
+	lcCache      struct{}
+)
+
+// newHookedApp returns an application whose service depends on a repository depending on a
+// database, along with an independent cache, each appending a hook recorded in the log. Its
+// controller appends a hook too.
+func newHookedApp(t *testing.T, log *hookLog, opts ...Option) *App {
+	t.Helper()
+
+	root := &ctorModule{
+		providers: []ProviderConstructor{
+			func(lc *Lifecycle) *lcDatabase {
+				lc.Append(log.hook("database", nil))
+				return &lcDatabase{}
+			},
+			func(_ *lcDatabase, lc *Lifecycle) *lcRepository {
+				lc.Append(log.hook("repository", nil))
+				return &lcRepository{}
+			},
+			func(_ *lcRepository, lc *Lifecycle) *lcService {
+				lc.Append(log.hook("service", nil))
+				return &lcService{}
+			},
+			func(lc *Lifecycle) *lcCache {
+				lc.Append(log.hook("cache", nil))
+				return &lcCache{}
+			},
+		},
+		controllers: []ControllerConstructor{
+			func(_ *lcService, _ *lcCache, lc *Lifecycle) *testController {
+				lc.Append(log.hook("controller", nil))
+				return &testController{}
+			},
+		},
+	}
+
+	app, err := New(root, opts...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return app
+}
+
+// assertBefore reports an error unless each event happened before the next one.
+func assertBefore(t *testing.T, events []string, ordered ...string) {
+	t.Helper()
+
+	for i := 1; i < len(ordered); i++ {
+		prev, next := slices.Index(events, ordered[i-1]), slices.Index(events, ordered[i])
+		if (prev < 0) || (next < 0) || (prev > next) {
+			t.Errorf("events = %v, want %q before %q", events, ordered[i-1], ordered[i])
+		}
+	}
+}
+
+func TestLifecycleDependencyOrder(t *testing.T) {
+	log := &hookLog{}
+	app := newHookedApp(t, log)
+
+	err := app.onStart()
+	if err != nil {
+		t.Fatalf("onStart() error = %v", err)
+	}
+
+	// the cache depends on nothing, so it starts with the database
+	started := log.take()
+	assertBefore(t, started, "start database", "start repository", "start service", "start controller")
+	assertBefore(t, started, "start cache", "start repository")
+
+	err = app.onStop(context.Background())
+	if err != nil {
+		t.Fatalf("onStop() error = %v", err)
+	}
+
+	stopped := log.take()
+	assertBefore(t, stopped, "stop controller", "stop service", "stop repository", "stop database")
+	assertBefore(t, stopped, "stop repository", "stop cache")
+}
+
+func TestLifecycleParallelism(t *testing.T) {
+	tests := []struct {
+		name        string
+		parallelism int
+		want        int
+	}{
+		{"unlimited", 0, 3},
+		{"limited", 2, 2},
+		{"sequential", 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu                sync.Mutex
+				running, maxCount int
+			)
+
+			// each hook waits a while for the others, so the ones allowed to run concurrently do
+			hook := LifecycleHook{
+				OnStart: func(context.Context) error {
+					mu.Lock()
+					running++
+					maxCount = max(maxCount, running)
+					mu.Unlock()
+
+					time.Sleep(50 * time.Millisecond)
+
+					mu.Lock()
+					running--
+					mu.Unlock()
+					return nil
+				},
+			}
+
+			root := &ctorModule{
+				providers: []ProviderConstructor{
+					func(lc *Lifecycle) *lcDatabase { lc.Append(hook); return &lcDatabase{} },
+					func(lc *Lifecycle) *lcCache { lc.Append(hook); return &lcCache{} },
+					func(lc *Lifecycle) *lcService { lc.Append(hook); return &lcService{} },
+				},
+				controllers: []ControllerConstructor{
+					func(*lcDatabase, *lcCache, *lcService) *testController { return &testController{} },
+				},
+			}
+
+			app, err := New(root, WithLifecycleParallelism(tt.parallelism))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			err = app.onStart()
+			if err != nil {
+				t.Fatalf("onStart() error = %v", err)
+			}
+			if maxCount != tt.want {
+				t.Errorf("hooks run concurrently = %d, want %d", maxCount, tt.want)
+			}
+		})
+	}
+}
+
+func TestLifecyclePreStop(t *testing.T) {
+	log := &hookLog{}
+	app := newHookedApp(t, log)
+
+	hooks := []string{"database", "repository", "service", "cache", "controller"}
+	for _, name := range hooks {
+		app.lifecycle.Append(LifecycleHook{
+			OnPreStop: func(context.Context) error {
+				log.record("pre-stop " + name)
+				return nil
+			},
+		})
+	}
+
+	err := app.onStart()
+	if err != nil {
+		t.Fatalf("onStart() error = %v", err)
+	}
+	log.take()
+
+	err = app.httpServer.Shutdown(context.Background())
+	if err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	// every hook is pre-stopped before any is stopped
+	events := log.take()
+	for _, name := range hooks {
+		assertBefore(t, events, "pre-stop "+name, "stop database")
+		assertBefore(t, events, "pre-stop "+name, "stop controller")
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	const (
+		drainDelay = 100 * time.Millisecond
+		timeout    = 50 * time.Millisecond
+	)
+
+	var (
+		stopErr      error
+		stopDeadline time.Time
+	)
+	root := &ctorModule{
+		controllers: []ControllerConstructor{
+			func(lc *Lifecycle) *testController {
+				lc.Append(LifecycleHook{
+					OnPreStop: func(context.Context) error {
+						time.Sleep(drainDelay)
+						return nil
+					},
+					OnStop: func(ctx context.Context) error {
+						stopErr = ctx.Err()
+						stopDeadline, _ = ctx.Deadline()
+						return nil
+					},
+				})
+				return &testController{}
+			},
+		},
+	}
+
+	app, err := New(root, WithShutdownTimeout(timeout))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	err = app.onStart()
+	if err != nil {
+		t.Fatalf("onStart() error = %v", err)
+	}
+
+	// the drain takes longer than the shutdown timeout, which only starts once it is over
+	start := time.Now()
+	err = app.Shutdown(context.Background())
+	if err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if stopErr != nil {
+		t.Errorf("OnStop() context error = %v, want the shutdown timeout to start after the drain", stopErr)
+	}
+	if wantAfter := start.Add(drainDelay + timeout); stopDeadline.Before(wantAfter) {
+		t.Errorf("OnStop() context deadline = %v, want after %v", stopDeadline, wantAfter)
+	}
+}
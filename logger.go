@@ -0,0 +1,53 @@
+package vara
+
+import (
+	"log/slog"
+	"reflect"
+
+	"go.uber.org/dig"
+)
+
+// loggerName is the name the application's logger is provided under in the root scope.
+const loggerName = "vara.logger"
+
+var loggerType = reflect.TypeOf((*slog.Logger)(nil))
+
+// LoggerConstructor is a function that takes any number of dependencies
+// as its parameters and returns a *slog.Logger and may optionally return
+// an error to indicate that it failed to build the value.
+//
+// Any arguments that the constructor has are treated as its dependencies. The dependencies are instantiated
+// in an unspecified order along with any dependencies that they might have.
+type LoggerConstructor constructor
+
+// GlobalLogger annotates a logger constructor so the logger becomes the application's
+// logger. It can be listed in ProviderConstructors of any module.
+//
+// Vara logs its own messages with the application's logger, and every module is provided a
+// child *slog.Logger with a `module` attribute, unless the module provides or imports its own
+// *slog.Logger. [slog.Default] is used if no logger is provided.
+//
+// Example:
+//
+//	ProviderConstructors: []vara.ProviderConstructor{
+//		vara.GlobalLogger(func() *slog.Logger {
+//			return slog.New(slog.NewJSONHandler(os.Stdout, nil))
+//		}),
+//	}
+func GlobalLogger(ctor LoggerConstructor) ProviderConstructor {
+	return Provide(ctor, Name(loggerName), Global())
+}
+
+// loggerInput is used for injecting the application's logger.
+type loggerInput struct {
+	dig.In
+	Logger *slog.Logger `name:"vara.logger" optional:"true"`
+}
+
+// get returns the application's logger, or [slog.Default] if none was provided.
+func (in loggerInput) get() *slog.Logger {
+	if in.Logger == nil {
+		return slog.Default()
+	}
+	return in.Logger
+}
@@ -0,0 +1,142 @@
+package vara
+
+// MetadataEntry is a key-value pair of metadata set on a route, controller or module.
+type MetadataEntry struct {
+	Key   string
+	Value any
+}
+
+// SetMetadata returns a metadata entry setting the key to the value. It is the building
+// block of decorator-style helpers, read back by guards using a [Reflector]:
+//
+//	func Public() vara.MetadataEntry { return vara.SetMetadata("public", true) }
+//
+//	func Roles(roles ...string) vara.MetadataEntry { return vara.SetMetadata("roles", roles) }
+//
+//	RouteConfigs: []*vara.RouteConfig{
+//		{
+//			Pattern:  "/users",
+//			Method:   http.MethodGet,
+//			Handler:  http.HandlerFunc(c.listUsers),
+//			Metadata: vara.Metadata{Roles("admin")},
+//		},
+//	}
+func SetMetadata(key string, value any) MetadataEntry {
+	return MetadataEntry{Key: key, Value: value}
+}
+
+// metadataPublic is the metadata key set by [Public].
+const metadataPublic = "vara.public"
+
+// Public marks a route, or every route of a controller or module, as public. Global and module
+// guards are skipped on public routes, while the guards of their controller and route still run.
+func Public() MetadataEntry {
+	return SetMetadata(metadataPublic, true)
+}
+
+// Metadata is the list of metadata entries set on a route, controller or module.
+type Metadata []MetadataEntry
+
+// Get returns the value of the key. The last entry wins if the key was set more than once.
+func (m Metadata) Get(key string) (any, bool) {
+	for i := len(m) - 1; i >= 0; i-- {
+		if m[i].Key == key {
+			return m[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// Reflector reads the metadata of the route handling a request. It is
+// available to every provider, e.g. to guards implementing role checks:
+//
+//	func (g *RolesGuard) Allow(gCtx vara.GuardContext) (bool, error) {
+//		roles, ok := vara.GetAllAndOverride[[]string](g.reflector, gCtx.Context(), "roles")
+//		if !ok {
+//			return true, nil
+//		}
+//		...
+//	}
+type Reflector struct{}
+
+func newReflector() *Reflector {
+	return &Reflector{}
+}
+
+// Get returns the value of the key set on the route handling the request.
+func (r *Reflector) Get(c *Context, key string) (any, bool) {
+	if c == nil {
+		return nil, false
+	}
+	return c.Route.RouteConfig.Metadata.Get(key)
+}
+
+// GetAll returns the values of the key set on the route handling the request, its
+// controller and its module, in that order. Levels that don't set the key are skipped.
+func (r *Reflector) GetAll(c *Context, key string) []any {
+	if c == nil {
+		return nil
+	}
+
+	var values []any
+	for _, m := range getMetadataLevels(c.Route) {
+		if v, ok := m.Get(key); ok {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// GetAllAndOverride returns the value of the key set on the route handling the request,
+// overriding the value set on its controller, which overrides the value set on its module.
+func (r *Reflector) GetAllAndOverride(c *Context, key string) (any, bool) {
+	if c == nil {
+		return nil, false
+	}
+	return getRouteMetadata(c.Route, key)
+}
+
+// getRouteMetadata returns the value of the key set on the route,
+// overriding the value set on its controller and module.
+func getRouteMetadata(route RouteInfo, key string) (any, bool) {
+	for _, m := range getMetadataLevels(route) {
+		if v, ok := m.Get(key); ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// getMetadataLevels returns the metadata of the route, its controller and its module, in that order.
+func getMetadataLevels(route RouteInfo) []Metadata {
+	return []Metadata{
+		route.RouteConfig.Metadata,
+		route.ControllerConfig.Metadata,
+		route.ModuleConfig.Metadata,
+	}
+}
+
+// Get is like [Reflector.Get], but returns the value as a T. It reports false
+// if the value was not found or is not of type T.
+//
+//	limit, ok := vara.Get[int](g.reflector, gCtx.Context(), "limit")
+func Get[T any](r *Reflector, c *Context, key string) (T, bool) {
+	return Reflect[T](r.Get(c, key))
+}
+
+// GetAllAndOverride is like [Reflector.GetAllAndOverride], but returns the value
+// as a T. It reports false if the value was not found or is not of type T.
+//
+//	roles, ok := vara.GetAllAndOverride[[]string](g.reflector, gCtx.Context(), "roles")
+func GetAllAndOverride[T any](r *Reflector, c *Context, key string) (T, bool) {
+	return Reflect[T](r.GetAllAndOverride(c, key))
+}
+
+// Reflect converts a metadata value read by a [Reflector] to its type. It reports
+// false if the value was not found or is not of type T.
+//
+//	isPublic, _ := vara.Reflect[bool](reflector.GetAllAndOverride(ctx, "public"))
+func Reflect[T any](value any, found bool) (T, bool) {
+	v, ok := value.(T)
+	return v, (found && ok)
+}
@@ -1,7 +1,12 @@
 package vara
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
 
 	"go.uber.org/dig"
 )
@@ -19,31 +24,147 @@ type module struct {
 	Module
 	scope       scope
 	parent      *module
+	importers   []*module
 	imports     []*module
+	registry    moduleRegistry
+	exports     *groupExports
+	bootstrap   *bootstrap
 	controllers []*controller
+
+	// providesLogger reports whether the module provides its own *slog.Logger, or
+	// imports one, instead of the logger Vara provides every module.
+	providesLogger bool
+
+	// guards are the guards applied to the module's routes, once resolved.
+	guards          []*guard
+	guardsResolved  bool
+	guardsResolving bool
+
+	// exportedGroups are the keys of the grouped values the module exports, under their export names.
+	exportedGroups []exportKey
+
+	// cors is the CORS policy applied to the module's routes, once resolved.
+	cors          *corsPolicy
+	corsResolved  bool
+	corsResolving bool
+}
+
+// moduleToken identifies a module by its type and value.
+type moduleToken struct {
+	typ   reflect.Type
+	value any
+}
+
+// getModuleToken returns the token identifying a module. Modules of the same type are
+// the same module if their values are equal, e.g. two separately constructed
+// `&database.Module{}` imports, while differently configured modules are not.
+//
+// Modules whose values can't be compared are identified by their address.
+func getModuleToken(m Module) moduleToken {
+	v := reflect.ValueOf(m)
+	t := moduleToken{typ: v.Type()}
+
+	switch {
+	case (v.Kind() == reflect.Pointer) && (!v.IsNil()) && (v.Elem().Comparable()):
+		t.value = v.Elem().Interface()
+	case (v.Kind() == reflect.Pointer):
+		t.value = v.Pointer()
+	case v.Comparable():
+		t.value = m
+	default:
+		t.value = GetToken(m)
+	}
+
+	return t
+}
+
+// getModuleName returns a readable name of a module, e.g. "auth" for an *auth.Module, or
+// "app.UserModule" for an *app.UserModule.
+func getModuleName(m Module) string {
+	t := reflect.TypeOf(m)
+	for (t != nil) && (t.Kind() == reflect.Pointer) {
+		t = t.Elem()
+	}
+	if (t == nil) || (t.Name() == "") {
+		return GetToken(m)
+	}
+
+	pkg := t.PkgPath()
+	pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+	if (t.Name() == "Module") && (pkg != "") {
+		return pkg
+	}
+	return strings.TrimPrefix(pkg+"."+t.Name(), ".")
 }
 
-func newModule(m Module, s scope) (*module, error) {
+// moduleRegistry holds every module built for an application by its token, so that
+// a module imported in several places is only built once.
+type moduleRegistry map[moduleToken]*module
+
+var (
+	// ErrImportCycle indicates that modules import each other without a [ForwardRef].
+	ErrImportCycle = errors.New("module import cycle")
+
+	// ErrExportNotImported indicates that a module exports a module it does not import.
+	ErrExportNotImported = errors.New("exported module is not imported")
+)
+
+func newModule(m Module, s scope, parent *module, b *bootstrap) (*module, error) {
 	var (
 		err error
 		mod = &module{
-			scope:  s,
-			Module: m,
+			scope:     s,
+			Module:    m,
+			exports:   &groupExports{},
+			registry:  moduleRegistry{},
+			bootstrap: b,
 		}
 	)
 
+	if parent != nil {
+		mod.registry = parent.registry
+		mod.exports = parent.exports
+	}
+	mod.registry[getModuleToken(m)] = mod
+
+	err = mod._assignParent(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	err = mod._registerInjector()
+	if err != nil {
+		return nil, fmt.Errorf("could not register injector: %w", err)
+	}
+
 	for _, imported := range m.Config().Imports {
-		subMod, err := newModule(imported, mod._newChildScope(imported))
-		if err != nil {
-			return nil, fmt.Errorf("could not build module: %w", imported, err)
+		ref, isForwardRef := imported.(*forwardRef)
+		if isForwardRef {
+			imported = ref.resolve()
 		}
 
-		err = subMod._assignParent(mod)
-		if err != nil {
-			return nil, err
+		if mod._isBuilding(imported) {
+			// the referenced module is being built further up the import chain, so
+			// its scope, and the providers in it, are already visible to this module.
+			if isForwardRef {
+				continue
+			}
+			return nil, fmt.Errorf("%w: %s", ErrImportCycle, mod._importPath(imported))
 		}
 
-		err = subMod._registerExportedProviders()
+		// modules are singletons; a module that was already
+		// imported elsewhere only shares its exports with this module.
+		subMod, isBuilt := mod.registry[getModuleToken(imported)]
+		if !isBuilt {
+			subMod, err = newModule(imported, mod._newChildScope(imported), mod, b)
+			if err != nil {
+				return nil, fmt.Errorf("could not build module (%T): %w", imported, err)
+			}
+		}
+
+		subMod.importers = append(subMod.importers, mod)
+
+		err = subMod._registerExportedProviders(mod)
 		if err != nil {
 			return nil, fmt.Errorf("could not register exported providers: %w", err)
 		}
@@ -54,9 +175,17 @@ func newModule(m Module, s scope) (*module, error) {
 		return nil, fmt.Errorf("could not register providers: %w", err)
 	}
 
-	err = mod._registerControllers()
+	err = mod._registerLogger()
 	if err != nil {
-		return nil, fmt.Errorf("could not register controllers: %w", err)
+		return nil, fmt.Errorf("could not register logger: %w", err)
+	}
+
+	if parent == nil {
+		// every module is built, so every importer of grouped exports is known
+		err = mod.exports.register()
+		if err != nil {
+			return nil, fmt.Errorf("could not register exported groups: %w", err)
+		}
 	}
 
 	return mod, err
@@ -71,30 +200,169 @@ func (m *module) _assignParent(parent *module) error {
 	return nil
 }
 
+// _isBuilding reports whether the module or one of its ancestors is an instance of mod.
+func (m *module) _isBuilding(mod Module) bool {
+	for curr := m; curr != nil; curr = curr.parent {
+		if getModuleToken(curr.Module) == getModuleToken(mod) {
+			return true
+		}
+	}
+	return false
+}
+
+// _hasAncestorIn reports whether one of the module's ancestors is among the modules.
+func (m *module) _hasAncestorIn(modules []*module) bool {
+	for curr := m.parent; curr != nil; curr = curr.parent {
+		if slices.Contains(modules, curr) {
+			return true
+		}
+	}
+	return false
+}
+
+// _importPath returns the chain of imports from the root module to mod, e.g.
+// "*app.Module -> *auth.Module -> *user.Module -> *auth.Module".
+func (m *module) _importPath(mod Module) string {
+	path := []string{GetToken(mod)}
+	for curr := m; curr != nil; curr = curr.parent {
+		path = append([]string{GetToken(curr.Module)}, path...)
+	}
+	return strings.Join(path, " -> ")
+}
+
+// _registerInjector makes an injector that resolves from the module's scope available
+// to the constructors provided to it, which bind their [Lazy] dependencies to it.
+func (m *module) _registerInjector() error {
+	return m.scope.Provide(func() *injector { return newInjector(m.bootstrap.mu, m.scope) })
+}
+
+// _registerLogger provides the module with a child of the application's logger, adding
+// a `module` attribute to its records, unless the module provides its own logger.
+func (m *module) _registerLogger() error {
+	if m.providesLogger {
+		return nil
+	}
+
+	return m.scope.Provide(func(in loggerInput) *slog.Logger {
+		return in.get().With("module", getModuleName(m.Module))
+	})
+}
+
+// _noteProvided records whether the keys of the values provided in the module's scope include a *slog.Logger.
+func (m *module) _noteProvided(keys ...exportKey) {
+	for _, key := range keys {
+		if key == (exportKey{typ: loggerType}) {
+			m.providesLogger = true
+		}
+	}
+}
+
 func (m *module) _newChildScope(mod Module) scope {
 	return m.scope.Scope(GetToken(mod))
 }
 
-func (m *module) _isExportedProvider(provider ProviderConstructor) bool {
+// _getExportKeys returns the keys of the values provided by the module's export constructors.
+func (m *module) _getExportKeys() []exportKey {
+	var keys []exportKey
+	for _, export := range m.Config().ExportConstructors {
+		exportKeys, _ := getExportKeys(export)
+		keys = append(keys, exportKeys...)
+	}
+	return keys
+}
+
+// _isExportedProvider reports whether the provider, registering the values identified by
+// keys, is exported: listed by the module's export constructors, or registering a value
+// that one of them provides.
+func (m *module) _isExportedProvider(provider ProviderConstructor, keys, exported []exportKey) bool {
 	for _, export := range m.Config().ExportConstructors {
 		if GetToken(export) == GetToken(provider) {
 			return true
 		}
 	}
-	return false
+	return overlaps(keys, exported)
 }
 
-func (m *module) _registerProviders() error {
-	mCfg := m.Config()
+// _getExportOnlyConstructors returns the export constructors providing values that none of the
+// module's provider constructors provide. They are provided in the module's scope too, so every
+// importer shares the module's instances.
+func (m *module) _getExportOnlyConstructors() []ProviderConstructor {
+	var (
+		mCfg     = m.Config()
+		provided []exportKey
+		only     []ProviderConstructor
+	)
+
 	for _, pvdCtor := range mCfg.ProviderConstructors {
-		isGlobExport := (mCfg.IsGlobal && m._isExportedProvider(pvdCtor))
-		// a global module's exported providers
-		// should be made available to all available scopes
-		err := m.scope.Provide(pvdCtor, dig.Export(isGlobExport))
+		keys, _ := getExportKeys(pvdCtor)
+		provided = append(provided, keys...)
+	}
+
+	for _, export := range mCfg.ExportConstructors {
+		keys, ok := getExportKeys(export)
+		if !ok {
+			// values that can't be forwarded are built in the importers' scopes instead
+			continue
+		}
+
+		isProvided := overlaps(keys, provided) || slices.ContainsFunc(mCfg.ProviderConstructors, func(p ProviderConstructor) bool {
+			return GetToken(p) == GetToken(export)
+		})
+		if !isProvided {
+			only = append(only, export)
+			provided = append(provided, keys...)
+		}
+	}
+
+	return only
+}
+
+func (m *module) _registerProviders() error {
+	var (
+		mCfg     = m.Config()
+		exported = m._getExportKeys()
+		ctors    = append(slices.Clip(mCfg.ProviderConstructors), m._getExportOnlyConstructors()...)
+	)
+
+	for _, pvdCtor := range ctors {
+		var (
+			err        error
+			keys, _    = getExportKeys(pvdCtor)
+			isExported = m._isExportedProvider(pvdCtor, keys, exported)
+		)
+
+		if (isExported) && (!mCfg.IsGlobal) && hasGroup(keys) {
+			err = m._registerExportedGroupProvider(pvdCtor)
+		} else {
+			// a global module's exported providers
+			// should be made available to all available scopes
+			err = m._provide(pvdCtor, dig.Export(mCfg.IsGlobal && isExported))
+		}
 		if err != nil {
-			return fmt.Errorf("error providing provider (%T): %w", pvdCtor, err)
+			return fmt.Errorf("error providing provider (%s): %w", GetToken(pvdCtor), err)
 		}
+
+		m._noteProvided(keys...)
+	}
+	return nil
+}
+
+// _registerAllControllers registers the controllers of the module's imports and
+// then its own. It is called once every module's providers have been registered, so
+// controllers may depend on providers of modules imported through a [ForwardRef].
+func (m *module) _registerAllControllers() error {
+	for _, imported := range m.imports {
+		err := imported._registerAllControllers()
+		if err != nil {
+			return err
+		}
+	}
+
+	err := m._registerControllers()
+	if err != nil {
+		return fmt.Errorf("could not register controllers (%T): %w", m.Module, err)
 	}
+
 	return nil
 }
 
@@ -108,7 +376,8 @@ func (m *module) _registerControllers() error {
 	)
 
 	for _, ctrlCtor := range mCfg.ControllerConstructors {
-		err := m.scope.Provide(ctrlCtor, opts...)
+		ctor, lazyOpts := bindLazy(ctrlCtor)
+		err := m.scope.Provide(ctor, append(opts, lazyOpts...)...)
 		if err != nil {
 			return fmt.Errorf("error providing controller (%T): %w", ctrlCtor, err)
 		}
@@ -128,22 +397,230 @@ func (m *module) _registerControllers() error {
 	)
 }
 
-// _registerExportedProviders registers the current module's exports in it's parent scope
-func (m *module) _registerExportedProviders() error {
+// _getGuards returns the guards applied to the routes of the module's controllers: the guards
+// applied to the routes of every module importing it, in the order they import it, followed by
+// its own. Since a module imported by several modules is only built once, its routes are guarded
+// by the guards of each of them, whatever the import order, and guards they share run once.
+func (m *module) _getGuards() ([]*guard, error) {
+	if m.guardsResolved {
+		return m.guards, nil
+	}
+	if m.guardsResolving {
+		// the module imports one of its importers, whose guards are being resolved already
+		return nil, nil
+	}
+
+	m.guardsResolving = true
+	defer func() { m.guardsResolving = false }()
+
+	var guards []*guard
+	for _, importer := range m.importers {
+		importerGuards, err := importer._getGuards()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, g := range importerGuards {
+			if !slices.Contains(guards, g) {
+				guards = append(guards, g)
+			}
+		}
+	}
+
+	mCfg := m.Config()
+	own, err := resolveGuards(m.scope, mCfg.Guards, mCfg.GuardConstructors)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving module guards (%T): %w", m.Module, err)
+	}
+
+	m.guards = append(guards, own...)
+	m.guardsResolved = true
+
+	return m.guards, nil
+}
+
+// _getCORS returns the CORS policy applied to the routes of the module's controllers: its own,
+// or the policy applied to the routes of the modules importing it. Since a module imported by
+// several modules is only built once, its routes can only have one policy, so a module without
+// its own config can't be imported by modules applying different policies. It returns nil if
+// there is none.
+func (m *module) _getCORS() (*corsPolicy, error) {
+	if m.corsResolved {
+		return m.cors, nil
+	}
+
+	m.corsResolving = true
+	defer func() { m.corsResolving = false }()
+
+	policy, err := newCORSPolicy(m.scope, m.Config().CORS)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving module CORS config (%T): %w", m.Module, err)
+	}
+
+	if policy == nil {
+		var from *module
+		for _, importer := range m.importers {
+			if importer.corsResolving {
+				// the module imports one of its importers, whose policy is being resolved already
+				continue
+			}
+
+			importerPolicy, err := importer._getCORS()
+			if err != nil {
+				return nil, err
+			}
+
+			if (from != nil) && (importerPolicy != policy) {
+				return nil, fmt.Errorf(
+					"%w: module (%T) is imported by modules with different CORS configs (%T, %T), set its own config",
+					ErrConflictingCORS, m.Module, from.Module, importer.Module,
+				)
+			}
+			policy, from = importerPolicy, importer
+		}
+	}
+
+	m.cors = policy
+	m.corsResolved = true
+
+	return m.cors, nil
+}
+
+// _provide registers a provider constructor in the module's scope, and with the bootstrap
+// tracking the dependencies between the application's providers.
+func (m *module) _provide(ctor ProviderConstructor, opts ...dig.ProvideOption) error {
+	if m.bootstrap == nil {
+		return provide(m.scope, ctor, opts...)
+	}
+
+	spec := getProviderSpec(ctor)
+	wrapped, wrapOpts, ok := m.bootstrap.wrap(m.scope, spec)
+	if !ok {
+		return provide(m.scope, ctor, opts...)
+	}
+
+	opts = append(append(opts, wrapOpts...), spec.options()...)
+	return m.scope.Provide(wrapped, opts...)
+}
+
+// _registerExportedGroupProvider registers a grouped provider that the module exports, with its
+// grouped values registered under export names instead. The values registered under those names
+// are added to their groups once every module is built, see [groupExports].
+func (m *module) _registerExportedGroupProvider(pvdCtor ProviderConstructor) error {
+	var (
+		err  error
+		keys []exportKey
+		spec = getProviderSpec(pvdCtor)
+	)
+
+	if spec.group != "" {
+		name := m.exports.newName(spec.group)
+		keys, _ = getExportKeys(spec)
+		for i := range keys {
+			keys[i].name = name
+		}
+		err = m._provide(Provide(spec.ctor, Name(name), As(spec.as...)))
+	} else {
+		// the grouped values are fields of `dig.Out` structs
+		var ctor constructor
+		ctor, keys = renameExportedGroups(spec.ctor, m.exports.newName)
+		err = m._provide(ctor)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		m.exportedGroups = append(m.exportedGroups, key)
+		m.exports.add(m, key, m)
+	}
+
+	return nil
+}
+
+// _registerExportedProviders registers the current module's exports in the importer's scope.
+//
+// Exported values are resolved from the module's own scope, so every importer shares the
+// module's instances and exported providers may depend on the module's internal providers.
+func (m *module) _registerExportedProviders(importer *module) error {
 	mCfg := m.Config()
 	// a global module's exports would be
 	// available to all available scopes already.
-	// so, no need to provide it to parent module's scope
-	if (mCfg.IsGlobal) || (m.parent == nil) {
+	// so, no need to provide it to the importer's scope
+	if (mCfg.IsGlobal) || (importer == nil) {
 		return nil
 	}
 
+	var forwarded []exportKey
 	for _, pvdCtor := range mCfg.ExportConstructors {
-		err := m.parent.scope.Provide(pvdCtor)
+		keys, ok := getExportKeys(pvdCtor)
+		if !ok {
+			// values that can't be forwarded are built in the importer's scope instead
+			err := provide(importer.scope, pvdCtor)
+			if err != nil {
+				return fmt.Errorf("error providing export (%s): %w", GetToken(pvdCtor), err)
+			}
+			continue
+		}
+
+		for _, key := range keys {
+			// grouped values are forwarded under their export names once every module is built
+			if (key.group != "") || slices.Contains(forwarded, key) {
+				continue
+			}
+			forwarded = append(forwarded, key)
+
+			err := importer.scope.Provide(newExportForwarder(m.scope, key))
+			if err != nil {
+				return fmt.Errorf("error providing export (%s): %w", GetToken(pvdCtor), err)
+			}
+			importer._noteProvided(key)
+		}
+	}
+
+	for _, key := range m.exportedGroups {
+		m.exports.add(m, key, importer)
+	}
+
+	for _, export := range mCfg.Exports {
+		exportedMod, ok := export.(Module)
+		if !ok {
+			continue
+		}
+
+		reExported, err := m._getImportedModule(exportedMod)
 		if err != nil {
-			return fmt.Errorf("error providing export (%T): %w", pvdCtor, err)
+			return err
+		}
+
+		// re-exporting a module forwards its exports one level up
+		err = reExported._registerExportedProviders(importer)
+		if err != nil {
+			return fmt.Errorf("error re-exporting module (%T): %w", exportedMod, err)
 		}
 	}
 
 	return nil
 }
+
+// _getImportedModule returns the built instance of a module imported by the module.
+func (m *module) _getImportedModule(mod Module) (*module, error) {
+	if ref, ok := mod.(*forwardRef); ok {
+		mod = ref.resolve()
+	}
+
+	token := getModuleToken(mod)
+	for _, imported := range m.Config().Imports {
+		if ref, ok := imported.(*forwardRef); ok {
+			imported = ref.resolve()
+		}
+		if getModuleToken(imported) != token {
+			continue
+		}
+		if built, ok := m.registry[token]; ok {
+			return built, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %T", ErrExportNotImported, mod)
+}
@@ -15,10 +15,23 @@ type ModuleConfig struct {
 
 	// Exports lists providers from this module that should be accessible to
 	// other modules that import this module.
+	//
+	// Exports may also contain modules imported by this module. Re-exporting a module
+	// makes its exports accessible to modules importing this module, so a module can
+	// bundle several modules and expose them through a single import.
 	Exports []Provider
 
+	// Metadata holds metadata associated with the module's routes, read with a [Reflector].
+	// It is overridden by the metadata of the routes and their controllers.
+	Metadata Metadata
+
 	// ExportConstructors lists constructors for providers that should be accessible
 	// in other modules importing this module.
+	//
+	// Exports are identified by the types, names and groups of the values they provide: a
+	// provider constructor providing one of them is exported, and an export constructor that
+	// no provider constructor matches is provided by the module itself. Every importer shares
+	// the module's instances.
 	ExportConstructors []ProviderConstructor
 
 	// Providers lists the providers within the module that are shared across
@@ -29,6 +42,20 @@ type ModuleConfig struct {
 	// will create and share within this module.
 	ProviderConstructors []ProviderConstructor
 
+	// Guards contains guard instances applied to every route of the module's
+	// controllers and of the controllers of the modules it imports. A module
+	// imported by several modules is guarded by the guards of each of them.
+	Guards []Guard
+
+	// GuardConstructors provides constructors for creating the module's guards that
+	// requires dependency injection.
+	GuardConstructors []GuardConstructor
+
+	// CORS configures Cross-Origin Resource Sharing for the routes of the module's controllers
+	// and of the controllers of the modules it imports, overriding the application's config. A
+	// module imported by modules with different configs must set its own.
+	CORS *CORSConfig
+
 	// Controllers lists the handlers defined in this module, which handle
 	// HTTP requests and define the module's endpoints.
 	Controllers []Controller
@@ -0,0 +1,141 @@
+package vara
+
+import (
+	"context"
+	"net/http"
+)
+
+// RouteObserver observes the handling of requests on routes, e.g. to trace them.
+//
+// Each method is called when a step of handling a request begins. The returned context is
+// passed on to the rest of the step, and done is called once the step ends.
+type RouteObserver interface {
+	// ObserveRequest is called when a route receives a request. done is called
+	// with the status code of the response once the request is handled.
+	ObserveRequest(r *http.Request, route RouteInfo) (ctx context.Context, done func(status int))
+
+	// ObserveInterceptor is called before an interceptor handles a request.
+	ObserveInterceptor(ctx context.Context, route RouteInfo, i Interceptor) (c context.Context, done func())
+
+	// ObserveGuard is called before a guard checks a request. done is called with the guard's result.
+	ObserveGuard(ctx context.Context, route RouteInfo, g Guard) (c context.Context, done func(allowed bool, err error))
+}
+
+// RouteObserverConstructor is a function that takes any number of dependencies
+// as its parameters and returns a value that meets the `RouteObserver` interface
+// and may optionally return an error to indicate that it failed to build the value.
+//
+// Any arguments that the constructor has are treated as its dependencies. The dependencies are instantiated
+// in an unspecified order along with any dependencies that they might have.
+type RouteObserverConstructor constructor
+
+// GlobalRouteObserver annotates a route observer constructor so the observer observes every
+// route of the application. It can be listed in ProviderConstructors of any module.
+func GlobalRouteObserver(ctor RouteObserverConstructor) ProviderConstructor {
+	return Provide(ctor, Group(groupRouteObservers.String()), As(new(RouteObserver)), Global())
+}
+
+// routeObservers notifies a list of observers.
+type routeObservers []RouteObserver
+
+// observeRequest notifies the observers that a request was received, chaining the contexts
+// they return. The returned done function notifies the observers in reverse order.
+func (o routeObservers) observeRequest(r *http.Request, route RouteInfo) (*http.Request, func(status int)) {
+	dones := make([]func(int), 0, len(o))
+	for _, observer := range o {
+		ctx, done := observer.ObserveRequest(r, route)
+		r = r.WithContext(ctx)
+		dones = append(dones, done)
+	}
+
+	return r, func(status int) {
+		for i := len(dones) - 1; i >= 0; i-- {
+			if dones[i] != nil {
+				dones[i](status)
+			}
+		}
+	}
+}
+
+// observeInterceptor notifies the observers that an interceptor is handling a request.
+func (o routeObservers) observeInterceptor(r *http.Request, route RouteInfo, i Interceptor) (*http.Request, func()) {
+	dones := make([]func(), 0, len(o))
+	for _, observer := range o {
+		ctx, done := observer.ObserveInterceptor(r.Context(), route, i)
+		r = r.WithContext(ctx)
+		dones = append(dones, done)
+	}
+
+	return r, func() {
+		for i := len(dones) - 1; i >= 0; i-- {
+			if dones[i] != nil {
+				dones[i]()
+			}
+		}
+	}
+}
+
+// observeGuard notifies the observers that a guard is checking a request.
+func (o routeObservers) observeGuard(r *http.Request, route RouteInfo, g Guard) (*http.Request, func(bool, error)) {
+	dones := make([]func(bool, error), 0, len(o))
+	for _, observer := range o {
+		ctx, done := observer.ObserveGuard(r.Context(), route, g)
+		r = r.WithContext(ctx)
+		dones = append(dones, done)
+	}
+
+	return r, func(allowed bool, err error) {
+		for i := len(dones) - 1; i >= 0; i-- {
+			if dones[i] != nil {
+				dones[i](allowed, err)
+			}
+		}
+	}
+}
+
+// StatusRecorder is a response writer recording the status code and size of the response
+// written through it, for interceptors and observers reporting on responses.
+type StatusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+// NewStatusRecorder returns a recorder writing the response to w.
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{
+		status:         http.StatusOK,
+		ResponseWriter: w,
+	}
+}
+
+// Status returns the status code of the response, 200 OK if none was written.
+func (r *StatusRecorder) Status() int {
+	return r.status
+}
+
+// Bytes returns the number of bytes of the response body written so far.
+func (r *StatusRecorder) Bytes() int {
+	return r.bytes
+}
+
+func (r *StatusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *StatusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Unwrap returns the underlying response writer, for use by [http.ResponseController].
+func (r *StatusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,45 @@
+package vara
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusRecorder(t *testing.T) {
+	tests := []struct {
+		name       string
+		write      func(w http.ResponseWriter)
+		wantStatus int
+		wantBytes  int
+	}{
+		{"nothing written", func(http.ResponseWriter) {}, http.StatusOK, 0},
+		{"body only", func(w http.ResponseWriter) { _, _ = w.Write([]byte("hello")) }, http.StatusOK, 5},
+		{"status and body", func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte("hello"))
+			_, _ = w.Write([]byte(", world"))
+		}, http.StatusCreated, 12},
+		{"superfluous status", func(w http.ResponseWriter) {
+			_, _ = w.Write([]byte("hello"))
+			w.WriteHeader(http.StatusInternalServerError)
+		}, http.StatusOK, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := NewStatusRecorder(httptest.NewRecorder())
+			tt.write(rec)
+
+			if (rec.Status() != tt.wantStatus) || (rec.Bytes() != tt.wantBytes) {
+				t.Errorf("Status(), Bytes() = %d, %d, want %d, %d", rec.Status(), rec.Bytes(), tt.wantStatus, tt.wantBytes)
+			}
+		})
+	}
+
+	// the recorded writer is reachable by response controllers
+	w := httptest.NewRecorder()
+	if err := http.NewResponseController(NewStatusRecorder(w)).Flush(); (err != nil) || !w.Flushed {
+		t.Errorf("Flush() error = %v, flushed: %v", err, w.Flushed)
+	}
+}
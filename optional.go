@@ -0,0 +1,35 @@
+package vara
+
+import (
+	"reflect"
+
+	"go.uber.org/dig"
+)
+
+// Optional marks a constructor dependency as optional. If no provider for T is
+// available in the module's scope, Value is left as the zero value of T instead
+// of failing the build. A provider returning the zero value of T, e.g. a nil pointer,
+// can't be told apart from a missing one.
+//
+// Example:
+//
+//	func NewUserService(cache vara.Optional[*cache.Service]) *UserService {
+//		svc := &UserService{}
+//		if c, ok := cache.Get(); ok {
+//			svc.cache = c
+//		}
+//		return svc
+//	}
+type Optional[T any] struct {
+	dig.In
+
+	// Value is the resolved dependency, or the zero value of T if it was not provided.
+	Value T `optional:"true"`
+}
+
+// Get returns the resolved dependency and reports whether it was provided.
+//
+// A dependency whose provider returned the zero value of T is reported as not provided.
+func (o Optional[T]) Get() (T, bool) {
+	return o.Value, !reflect.ValueOf(&o.Value).Elem().IsZero()
+}
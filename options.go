@@ -0,0 +1,78 @@
+package vara
+
+import "time"
+
+// Option configures how an [App] is built.
+type Option func(*options)
+
+// options holds the settings applied by an [App]'s options.
+type options struct {
+	concurrentBuild      bool
+	bootstrapTimeout     time.Duration
+	lifecycleParallelism int
+	shutdownTimeout      time.Duration
+	globalGuards         []Guard
+	cors                 *CORSConfig
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithConcurrentBuild builds every provider of the application while it is created,
+// calling the constructors of providers that don't depend on each other concurrently.
+//
+// Without it, providers are built one after the other, and only when they are required
+// by a controller or another provider.
+func WithConcurrentBuild() Option {
+	return func(o *options) {
+		o.concurrentBuild = true
+	}
+}
+
+// WithBootstrapTimeout limits how long building the application may take. The context.Context
+// injected into constructors carries the deadline while the application is built. Once it is
+// exceeded, the context is cancelled and building the application fails, without waiting for
+// the constructors that are running, see [NewWithContext].
+func WithBootstrapTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.bootstrapTimeout = d
+	}
+}
+
+// WithLifecycleParallelism limits how many lifecycle hooks may run concurrently.
+// Hooks run one after the other if n is 1, and no limit is applied if n is zero.
+func WithLifecycleParallelism(n int) Option {
+	return func(o *options) {
+		o.lifecycleParallelism = n
+	}
+}
+
+// WithShutdownTimeout limits how long stopping the application's lifecycle hooks and its server
+// may take during a graceful shutdown, 5 seconds by default. The timeout starts once the hooks'
+// OnPreStop functions returned, so the time spent draining traffic is not taken from it.
+func WithShutdownTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.shutdownTimeout = d
+	}
+}
+
+// WithGlobalGuards applies the guards to every route of the application. Guards that
+// require dependency injection are registered with [GlobalGuard] instead.
+func WithGlobalGuards(guards ...Guard) Option {
+	return func(o *options) {
+		o.globalGuards = append(o.globalGuards, guards...)
+	}
+}
+
+// WithCORS configures Cross-Origin Resource Sharing for every route of the application,
+// unless overridden by the [CORSConfig] of the route's module or controller.
+func WithCORS(cfg CORSConfig) Option {
+	return func(o *options) {
+		o.cors = &cfg
+	}
+}
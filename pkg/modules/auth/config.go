@@ -0,0 +1,21 @@
+package auth
+
+type Config struct {
+	// APIKeyHeader is the header API keys are read from
+	APIKeyHeader string
+
+	// APIKeyQuery is the query param API keys are read from if the header is not set,
+	// or empty to only read them from the header, since query params are often logged
+	APIKeyQuery string
+
+	// Realm is the protection space Basic credentials are challenged for
+	Realm string
+}
+
+// NewConfig returns the default config, reading API keys from the X-API-Key header.
+func NewConfig() Config {
+	return Config{
+		APIKeyHeader: "X-API-Key",
+		Realm:        "restricted",
+	}
+}
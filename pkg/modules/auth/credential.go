@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"sync"
+)
+
+// Scheme is the scheme credentials are presented with.
+type Scheme string
+
+// supported Scheme
+const (
+	SchemeAPIKey = Scheme("api_key")
+	SchemeBasic  = Scheme("basic")
+)
+
+var (
+	// ErrMissingCredentials indicates a request carries no credentials
+	ErrMissingCredentials = errors.New("missing credentials")
+
+	// ErrInvalidCredentials indicates credentials do not match any of the credential store
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Credentials are the credentials a request is authenticated with.
+type Credentials struct {
+	// Scheme is the scheme the credentials are presented with
+	Scheme Scheme
+
+	// Username is the username of Basic credentials
+	Username string
+
+	// Secret is the API key, or the password of Basic credentials
+	Secret string
+}
+
+// Identity is the identity of authenticated credentials.
+type Identity struct {
+	// ID identifies the client of an API key, or the user of Basic credentials
+	ID string
+
+	// Scheme is the scheme the credentials were presented with
+	Scheme Scheme
+}
+
+// CredentialStore authenticates credentials.
+type CredentialStore interface {
+	// Authenticate returns the identity the credentials belong to, or
+	// [ErrInvalidCredentials] if they do not match any of the store.
+	Authenticate(ctx context.Context, c Credentials) (*Identity, error)
+}
+
+// credentials are API keys and users, with their secrets hashed.
+type credentials struct {
+	keys  []apiKey
+	users map[string]string
+}
+
+// apiKey is an API key identifying a client.
+type apiKey struct {
+	id     string
+	digest [sha256.Size]byte
+}
+
+// dummyHash is checked against when a user does not exist, so
+// authenticating unknown and known users takes about as long.
+var dummyHash = sync.OnceValue(func() string {
+	hash, _ := HashPassword("")
+	return hash
+})
+
+func (c *credentials) authenticate(cr Credentials) (*Identity, error) {
+	switch cr.Scheme {
+	case SchemeAPIKey:
+		// every key is compared so the time taken does not reveal the matched key
+		digest := sha256.Sum256([]byte(cr.Secret))
+		id, found := "", false
+		for _, k := range c.keys {
+			if (subtle.ConstantTimeCompare(digest[:], k.digest[:]) == 1) && !found {
+				id, found = k.id, true
+			}
+		}
+		if !found {
+			return nil, ErrInvalidCredentials
+		}
+		return &Identity{ID: id, Scheme: SchemeAPIKey}, nil
+
+	case SchemeBasic:
+		encoded, exists := c.users[cr.Username]
+		if !exists {
+			encoded = dummyHash()
+		}
+
+		ok, err := verifyHash(encoded, cr.Secret)
+		if err != nil {
+			return nil, err
+		}
+		if !ok || !exists {
+			return nil, ErrInvalidCredentials
+		}
+		return &Identity{ID: cr.Username, Scheme: SchemeBasic}, nil
+
+	default:
+		return nil, ErrInvalidCredentials
+	}
+}
+
+// MemoryStore is an in-memory [CredentialStore], e.g. for tests and development.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	creds credentials
+}
+
+// NewMemoryStore returns an empty in-memory credential store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		creds: credentials{
+			users: make(map[string]string),
+		},
+	}
+}
+
+// AddAPIKey adds an API key identifying the client with the id.
+func (s *MemoryStore) AddAPIKey(id, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.creds.keys = append(s.creds.keys, apiKey{id: id, digest: sha256.Sum256([]byte(key))})
+}
+
+// AddUser adds a user authenticated with the password, replacing the user's previous password.
+func (s *MemoryStore) AddUser(username, password string) error {
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.creds.users[username] = hash
+	return nil
+}
+
+func (s *MemoryStore) Authenticate(_ context.Context, c Credentials) (*Identity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.creds.authenticate(c)
+}
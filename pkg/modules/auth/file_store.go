@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// kinds of credentials file entries
+const (
+	entryKey  = "key"
+	entryUser = "user"
+)
+
+// fileCheckInterval is how often a [FileStore] checks if its file was modified.
+const fileCheckInterval = time.Second
+
+// FileStore is a [CredentialStore] reading hashed credentials from a file, which is read again
+// once modified so credentials are rotated without restarting the application. The file is
+// checked for modifications at most once per second.
+//
+// Each line of the file is an entry made of its kind, the identity and the hashed secret,
+// separated by spaces. Blank lines and lines starting with # are ignored:
+//
+//	# API keys, hashed with HashAPIKey
+//	key  billing  sha256$9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+//	# users, with passwords hashed with HashPassword
+//	user alice    pbkdf2-sha256$100000$<salt>$<key>
+type FileStore struct {
+	path    string
+	now     func() time.Time
+	mu      sync.RWMutex
+	creds   *credentials
+	modTime time.Time
+
+	// checkedAt is the Unix time in nanoseconds the file was last checked for modifications
+	checkedAt atomic.Int64
+}
+
+// NewFileStore returns the credential store reading the credentials file at path.
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{
+		path: path,
+		now:  time.Now,
+	}
+
+	_, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *FileStore) Authenticate(_ context.Context, c Credentials) (*Identity, error) {
+	creds, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return creds.authenticate(c)
+}
+
+// load returns the credentials of the file, reading it again if it was modified since last read.
+func (s *FileStore) load() (*credentials, error) {
+	s.mu.RLock()
+	creds, modTime := s.creds, s.modTime
+	s.mu.RUnlock()
+
+	now := s.now()
+	if (creds != nil) && (now.Sub(time.Unix(0, s.checkedAt.Load())) < fileCheckInterval) {
+		return creds, nil
+	}
+	s.checkedAt.Store(now.UnixNano())
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read credentials file: %w", err)
+	}
+
+	if (creds != nil) && info.ModTime().Equal(modTime) {
+		return creds, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the file may have been read again while waiting for the lock
+	if (s.creds != nil) && info.ModTime().Equal(s.modTime) {
+		return s.creds, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read credentials file: %w", err)
+	}
+
+	creds, err = parseCredentials(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse credentials file: %w", err)
+	}
+
+	s.creds, s.modTime = creds, info.ModTime()
+	return creds, nil
+}
+
+// parseCredentials returns the credentials of a credentials file.
+func parseCredentials(data []byte) (*credentials, error) {
+	creds := &credentials{
+		users: make(map[string]string),
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if (line == "") || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: expected kind, identity and hash", n)
+		}
+
+		kind, id, encoded := fields[0], fields[1], fields[2]
+		switch kind {
+		case entryKey:
+			// keys are looked up by comparing their digests, so they can't be salted
+			h, err := parseHash(encoded)
+			if (err != nil) || (h.scheme != schemeSHA256) {
+				return nil, fmt.Errorf("line %d: %w: API keys must be hashed with HashAPIKey", n, ErrInvalidHash)
+			}
+			creds.keys = append(creds.keys, apiKey{id: id, digest: [sha256.Size]byte(h.key)})
+
+		case entryUser:
+			_, err := parseHash(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			creds.users[id] = encoded
+
+		default:
+			return nil, fmt.Errorf("line %d: unknown kind %q", n, kind)
+		}
+	}
+
+	return creds, scanner.Err()
+}
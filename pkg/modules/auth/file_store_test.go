@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCredentials(t *testing.T) {
+	password, err := HashPassword("s3cret")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			data: "# comment\n\n" +
+				"key  billing " + HashAPIKey("k1") + "\n" +
+				"  user alice   " + password + "  \n",
+		},
+		{name: "missing hash", data: "key billing\n", wantErr: "line 1: expected kind, identity and hash"},
+		{name: "too many fields", data: "\nuser alice " + password + " extra\n", wantErr: "line 2: expected kind, identity and hash"},
+		{name: "unknown kind", data: "token billing " + HashAPIKey("k1"), wantErr: `line 1: unknown kind "token"`},
+		{name: "salted api key", data: "key billing " + password, wantErr: "line 1: invalid hash"},
+		{name: "invalid user hash", data: "user alice md5$abc", wantErr: "line 1: invalid hash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creds, err := parseCredentials([]byte(tt.data))
+			if tt.wantErr != "" {
+				if (err == nil) || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Errorf("parseCredentials() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCredentials() error = %v", err)
+			}
+
+			if (len(creds.keys) != 1) || (creds.keys[0].id != "billing") {
+				t.Errorf("keys = %+v, want the billing key", creds.keys)
+			}
+			if creds.users["alice"] != password {
+				t.Errorf("users = %v, want alice", creds.users)
+			}
+		})
+	}
+}
+
+func TestFileStoreAuthenticate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	write := func(data string, modTime time.Time) {
+		err := os.WriteFile(path, []byte(data), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.Chtimes(path, modTime, modTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("key billing "+HashAPIKey("k1")+"\n", time.Now().Add(-time.Hour))
+	s, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	authenticate := func(key string) error {
+		_, err := s.Authenticate(context.Background(), Credentials{Scheme: SchemeAPIKey, Secret: key})
+		return err
+	}
+
+	if err := authenticate("k1"); err != nil {
+		t.Errorf("Authenticate(k1) error = %v", err)
+	}
+
+	// the file is rotated, but only read again once checked for modifications
+	write("key billing "+HashAPIKey("k2")+"\n", now)
+	if err := authenticate("k1"); err != nil {
+		t.Errorf("Authenticate(k1) within a second of the last check error = %v", err)
+	}
+
+	now = now.Add(fileCheckInterval)
+	if err := authenticate("k1"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Authenticate(rotated k1) error = %v, want %v", err, ErrInvalidCredentials)
+	}
+	if err := authenticate("k2"); err != nil {
+		t.Errorf("Authenticate(k2) error = %v", err)
+	}
+}
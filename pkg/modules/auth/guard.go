@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/huboh/vara"
+)
+
+// identityKey is the key the identity of a request's credentials is stored under.
+var identityKey = vara.NewKey[*Identity]("auth.identity")
+
+// IdentityFromRequest returns the identity authenticated by an [APIKeyGuard] or a [BasicGuard] for the request, if any.
+func IdentityFromRequest(r *http.Request) (*Identity, bool) {
+	return identityKey.Get(vara.FromRequest(r))
+}
+
+// APIKeyGuard allows requests carrying a valid API key in the configured header, or query param if
+// set, storing its identity for [IdentityFromRequest]. Other requests are rejected with a 401 Unauthorized.
+type APIKeyGuard struct {
+	service *Service
+}
+
+// NewAPIKeyGuard returns a guard authenticating API keys with the service. It is a [vara.GuardConstructor].
+func NewAPIKeyGuard(s *Service) *APIKeyGuard {
+	return &APIKeyGuard{
+		service: s,
+	}
+}
+
+func (g *APIKeyGuard) Allow(gCtx vara.GuardContext) (bool, error) {
+	var (
+		r   = gCtx.Http.R
+		cfg = g.service.config
+		key = r.Header.Get(cfg.APIKeyHeader)
+	)
+
+	if (key == "") && (cfg.APIKeyQuery != "") {
+		key = r.URL.Query().Get(cfg.APIKeyQuery)
+	}
+
+	return authenticate(g.service, gCtx, Credentials{Scheme: SchemeAPIKey, Secret: key}, (key != ""), vara.ErrUnauthorized)
+}
+
+// BasicGuard allows requests carrying valid Basic credentials, as defined by RFC 7617, storing
+// their identity for [IdentityFromRequest]. Other requests are rejected with a 401 Unauthorized
+// challenging the client to authenticate in the configured realm.
+type BasicGuard struct {
+	service *Service
+}
+
+// NewBasicGuard returns a guard authenticating Basic credentials with the service. It is a [vara.GuardConstructor].
+func NewBasicGuard(s *Service) *BasicGuard {
+	return &BasicGuard{
+		service: s,
+	}
+}
+
+func (g *BasicGuard) Allow(gCtx vara.GuardContext) (bool, error) {
+	username, password, ok := gCtx.Http.R.BasicAuth()
+	challenge := vara.ErrUnauthorized.WithHeader(
+		"WWW-Authenticate",
+		fmt.Sprintf("Basic realm=%q, charset=\"UTF-8\"", g.service.config.Realm),
+	)
+
+	return authenticate(g.service, gCtx, Credentials{Scheme: SchemeBasic, Username: username, Secret: password}, ok, challenge)
+}
+
+// authenticate authenticates the credentials of a request, storing their identity if they are
+// valid and rejecting the request with the rejection if they are missing or invalid.
+func authenticate(s *Service, gCtx vara.GuardContext, c Credentials, present bool, rejection *vara.HTTPError) (bool, error) {
+	r := gCtx.Http.R
+	if !present {
+		s.fail(r, c, ErrMissingCredentials)
+		return false, rejection.Wrap(ErrMissingCredentials)
+	}
+
+	id, err := s.Authenticate(r, c)
+	if isRejection(err) {
+		return false, rejection.Wrap(err)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	identityKey.Set(gCtx.Context(), id)
+	return true, nil
+}
@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/huboh/vara"
+	"github.com/huboh/vara/pkg/modules/event"
+)
+
+// newTestService returns a service authenticating with the store, and the failures it emits.
+func newTestService(t *testing.T, s CredentialStore) (*Service, *[]Failure) {
+	t.Helper()
+
+	var (
+		failures []Failure
+		events   = event.NewService(event.NewConfig(), event.Observers{})
+	)
+
+	err := events.AddListener(&event.Listener{
+		Event: EventFailure,
+		Func: func(e event.Event) error {
+			failures = append(failures, e.Payload.(Failure))
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewService(NewConfig(), s, events, slog.New(slog.NewTextHandler(io.Discard, nil))), &failures
+}
+
+func newTestStore(t *testing.T) *MemoryStore {
+	t.Helper()
+
+	s := NewMemoryStore()
+	s.AddAPIKey("billing", "k1")
+	err := s.AddUser("alice", "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func guardContext(r *http.Request) vara.GuardContext {
+	return vara.GuardContext{Http: vara.GuardContextHttp{R: r, W: httptest.NewRecorder()}}
+}
+
+func TestGuards(t *testing.T) {
+	withKey := func(header, key string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/reports", nil)
+		r.Header.Set(header, key)
+		return r
+	}
+	withBasic := func(username, password string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/reports", nil)
+		r.SetBasicAuth(username, password)
+		return r
+	}
+
+	const basicChallenge = `Basic realm="restricted", charset="UTF-8"`
+
+	tests := []struct {
+		name          string
+		guard         func(*Service) vara.Guard
+		req           *http.Request
+		wantChallenge string
+		wantReason    error
+	}{
+		{"api key", toGuard(NewAPIKeyGuard), withKey("X-API-Key", "k1"), "", nil},
+		{"missing api key", toGuard(NewAPIKeyGuard), withKey("Authorization", "k1"), "", ErrMissingCredentials},
+		{"invalid api key", toGuard(NewAPIKeyGuard), withKey("X-API-Key", "k2"), "", ErrInvalidCredentials},
+		{"api key in query not allowed", toGuard(NewAPIKeyGuard), httptest.NewRequest(http.MethodGet, "/reports?api_key=k1", nil), "", ErrMissingCredentials},
+		{"basic", toGuard(NewBasicGuard), withBasic("alice", "s3cret"), "", nil},
+		{"missing basic", toGuard(NewBasicGuard), withKey("Authorization", "Bearer token"), basicChallenge, ErrMissingCredentials},
+		{"wrong password", toGuard(NewBasicGuard), withBasic("alice", "secret"), basicChallenge, ErrInvalidCredentials},
+		{"unknown user", toGuard(NewBasicGuard), withBasic("bob", "s3cret"), basicChallenge, ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, failures := newTestService(t, newTestStore(t))
+
+			ok, err := tt.guard(s).Allow(guardContext(tt.req))
+			if tt.wantReason == nil {
+				if !ok || (err != nil) {
+					t.Fatalf("Allow() = %v, %v, want true, nil", ok, err)
+				}
+				if len(*failures) != 0 {
+					t.Errorf("failures = %+v, want none", *failures)
+				}
+				return
+			}
+
+			var httpErr *vara.HTTPError
+			if ok || !errors.As(err, &httpErr) || (httpErr.Status != http.StatusUnauthorized) {
+				t.Fatalf("Allow() = %v, %v, want a 401 Unauthorized", ok, err)
+			}
+			if !errors.Is(err, tt.wantReason) {
+				t.Errorf("Allow() error = %v, want %v", err, tt.wantReason)
+			}
+			if got := httpErr.Header.Get("WWW-Authenticate"); got != tt.wantChallenge {
+				t.Errorf("WWW-Authenticate = %q, want %q", got, tt.wantChallenge)
+			}
+
+			if len(*failures) != 1 {
+				t.Fatalf("failures = %+v, want one", *failures)
+			}
+			f := (*failures)[0]
+			if !errors.Is(f.Reason, tt.wantReason) || (f.Path != "/reports") || (f.RemoteAddr != tt.req.RemoteAddr) {
+				t.Errorf("failure = %+v, want %v for the request", f, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestAPIKeyGuardQuery(t *testing.T) {
+	s, _ := newTestService(t, newTestStore(t))
+	s.config.APIKeyQuery = "api_key"
+
+	ok, err := NewAPIKeyGuard(s).Allow(guardContext(httptest.NewRequest(http.MethodGet, "/reports?api_key=k1", nil)))
+	if !ok || (err != nil) {
+		t.Errorf("Allow() = %v, %v, want true, nil", ok, err)
+	}
+}
+
+// storeError is a credential store failing to authenticate any credentials.
+type storeError struct{}
+
+func (storeError) Authenticate(_ context.Context, _ Credentials) (*Identity, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func TestGuardStoreError(t *testing.T) {
+	s, failures := newTestService(t, storeError{})
+
+	r := httptest.NewRequest(http.MethodGet, "/reports", nil)
+	r.SetBasicAuth("alice", "s3cret")
+
+	ok, err := NewBasicGuard(s).Allow(guardContext(r))
+	var httpErr *vara.HTTPError
+	if ok || (err == nil) || errors.As(err, &httpErr) {
+		t.Errorf("Allow() = %v, %v, want the store's error", ok, err)
+	}
+	if len(*failures) != 1 {
+		t.Errorf("failures = %+v, want one", *failures)
+	}
+}
+
+func toGuard[G vara.Guard](ctor func(*Service) G) func(*Service) vara.Guard {
+	return func(s *Service) vara.Guard { return ctor(s) }
+}
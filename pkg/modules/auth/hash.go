@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// recognized hash schemes
+const (
+	schemeSHA256 = "sha256"
+	schemePBKDF2 = "pbkdf2-sha256"
+)
+
+const (
+	// pbkdf2Iterations is the number of iterations of the passwords hashed by HashPassword
+	pbkdf2Iterations = 100_000
+
+	pbkdf2SaltSize = 16
+	pbkdf2KeySize  = sha256.Size
+)
+
+var (
+	// ErrInvalidHash indicates a hash is not in a recognized format
+	ErrInvalidHash = errors.New("invalid hash")
+)
+
+// HashPassword returns the PBKDF2-SHA256 hash of a password, in the
+// "pbkdf2-sha256$<iterations>$<salt>$<key>" format of credentials files.
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, pbkdf2SaltSize)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", err
+	}
+
+	key := pbkdf2([]byte(password), salt, pbkdf2Iterations, pbkdf2KeySize)
+	return strings.Join([]string{
+		schemePBKDF2,
+		strconv.Itoa(pbkdf2Iterations),
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	}, "$"), nil
+}
+
+// HashAPIKey returns the SHA-256 hash of an API key, in the "sha256$<hex>" format of credentials
+// files. API keys are expected to be long random strings, so a fast unsalted hash suffices.
+func HashAPIKey(key string) string {
+	digest := sha256.Sum256([]byte(key))
+	return schemeSHA256 + "$" + hex.EncodeToString(digest[:])
+}
+
+// hash is a decoded hash of a secret.
+type hash struct {
+	scheme string
+	iter   int
+	salt   []byte
+	key    []byte
+}
+
+// parseHash decodes a hash in the "sha256$<hex>" or "pbkdf2-sha256$<iterations>$<salt>$<key>" format.
+func parseHash(encoded string) (hash, error) {
+	scheme, params, _ := strings.Cut(encoded, "$")
+
+	switch scheme {
+	case schemeSHA256:
+		key, err := hex.DecodeString(params)
+		if (err != nil) || (len(key) != sha256.Size) {
+			return hash{}, ErrInvalidHash
+		}
+		return hash{scheme: scheme, key: key}, nil
+
+	case schemePBKDF2:
+		parts := strings.Split(params, "$")
+		if len(parts) != 3 {
+			return hash{}, ErrInvalidHash
+		}
+
+		iter, err := strconv.Atoi(parts[0])
+		if (err != nil) || (iter < 1) {
+			return hash{}, ErrInvalidHash
+		}
+
+		salt, err := base64.RawStdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return hash{}, ErrInvalidHash
+		}
+
+		key, err := base64.RawStdEncoding.DecodeString(parts[2])
+		if (err != nil) || (len(key) == 0) {
+			return hash{}, ErrInvalidHash
+		}
+		return hash{scheme: scheme, iter: iter, salt: salt, key: key}, nil
+
+	default:
+		return hash{}, fmt.Errorf("%w: unknown scheme %q", ErrInvalidHash, scheme)
+	}
+}
+
+// verifyHash reports if the secret matches the encoded hash, comparing them in constant time.
+func verifyHash(encoded, secret string) (bool, error) {
+	h, err := parseHash(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	var got []byte
+	if h.scheme == schemePBKDF2 {
+		got = pbkdf2([]byte(secret), h.salt, h.iter, len(h.key))
+	} else {
+		digest := sha256.Sum256([]byte(secret))
+		got = digest[:]
+	}
+
+	return subtle.ConstantTimeCompare(got, h.key) == 1, nil
+}
+
+// pbkdf2 derives a key of keyLen bytes from the password and salt, as defined by RFC 8018.
+func pbkdf2(password, salt []byte, iter, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hashLen := prf.Size()
+	blocks := (keyLen + hashLen - 1) / hashLen
+
+	var (
+		buf [4]byte
+		dk  = make([]byte, 0, (blocks * hashLen))
+		u   = make([]byte, hashLen)
+	)
+
+	for block := 1; block <= blocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(buf[:], uint32(block))
+		prf.Write(buf[:])
+		dk = prf.Sum(dk)
+
+		t := dk[(len(dk) - hashLen):]
+		copy(u, t)
+
+		for n := 2; n <= iter; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for i := range u {
+				t[i] ^= u[i]
+			}
+		}
+	}
+
+	return dk[:keyLen]
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestPBKDF2(t *testing.T) {
+	// PBKDF2-HMAC-SHA256 test vectors of RFC 7914, section 11, and the
+	// widely used vectors derived from those of RFC 6070
+	tests := []struct {
+		password, salt string
+		iter, keyLen   int
+		want           string
+	}{
+		{"passwd", "salt", 1, 64, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+		{"Password", "NaCl", 80000, 64, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"},
+		{"password", "salt", 1, 32, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{"password", "salt", 4096, 32, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+		{"passwordPASSWORDpassword", "saltSALTsaltSALTsaltSALTsaltSALTsalt", 4096, 40, "348c89dbcbd32b2f32d814b8116e84cf2b17347ebc1800181c4e2a1fb8dd53e1c635518c7dac47e9"},
+	}
+
+	for _, tt := range tests {
+		got := hex.EncodeToString(pbkdf2([]byte(tt.password), []byte(tt.salt), tt.iter, tt.keyLen))
+		if got != tt.want {
+			t.Errorf("pbkdf2(%q, %q, %d, %d) = %s, want %s", tt.password, tt.salt, tt.iter, tt.keyLen, got, tt.want)
+		}
+	}
+}
+
+func TestParseHash(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		want    hash
+		wantErr bool
+	}{
+		{
+			name:    "sha256",
+			encoded: "sha256$9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+			want:    hash{scheme: schemeSHA256},
+		},
+		{
+			name:    "pbkdf2",
+			encoded: "pbkdf2-sha256$1000$c2FsdA$a2V5",
+			want:    hash{scheme: schemePBKDF2, iter: 1000, salt: []byte("salt"), key: []byte("key")},
+		},
+		{name: "unknown scheme", encoded: "md5$098f6bcd4621d373cade4e832627b4f6", wantErr: true},
+		{name: "no scheme", encoded: "9f86d081884c7d659a2feaa0c55ad015", wantErr: true},
+		{name: "sha256 not hex", encoded: "sha256$zz86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", wantErr: true},
+		{name: "sha256 short digest", encoded: "sha256$9f86d081", wantErr: true},
+		{name: "pbkdf2 missing key", encoded: "pbkdf2-sha256$1000$c2FsdA", wantErr: true},
+		{name: "pbkdf2 zero iterations", encoded: "pbkdf2-sha256$0$c2FsdA$a2V5", wantErr: true},
+		{name: "pbkdf2 invalid iterations", encoded: "pbkdf2-sha256$many$c2FsdA$a2V5", wantErr: true},
+		{name: "pbkdf2 invalid salt", encoded: "pbkdf2-sha256$1000$!!$a2V5", wantErr: true},
+		{name: "pbkdf2 empty key", encoded: "pbkdf2-sha256$1000$c2FsdA$", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseHash(tt.encoded)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidHash) {
+					t.Errorf("parseHash() error = %v, want %v", err, ErrInvalidHash)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseHash() error = %v", err)
+			}
+
+			if (got.scheme != tt.want.scheme) || (got.iter != tt.want.iter) || (string(got.salt) != string(tt.want.salt)) {
+				t.Errorf("parseHash() = %+v, want %+v", got, tt.want)
+			}
+			if (tt.want.key != nil) && (string(got.key) != string(tt.want.key)) {
+				t.Errorf("parseHash() key = %x, want %x", got.key, tt.want.key)
+			}
+		})
+	}
+}
+
+func TestVerifyHash(t *testing.T) {
+	password, err := HashPassword("s3cret")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		encoded string
+		secret  string
+		want    bool
+	}{
+		{"password", password, "s3cret", true},
+		{"wrong password", password, "secret", false},
+		{"api key", HashAPIKey("test"), "test", true},
+		{"wrong api key", HashAPIKey("test"), "tset", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := verifyHash(tt.encoded, tt.secret)
+			if err != nil {
+				t.Fatalf("verifyHash() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("verifyHash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"cmp"
+	"errors"
+
+	"github.com/huboh/vara"
+	"github.com/huboh/vara/pkg/modules/event"
+)
+
+var (
+	// ErrNoCredentialStore indicates the module has neither a credential store nor a credentials file
+	ErrNoCredentialStore = errors.New("no credential store")
+)
+
+// Module provides the service authenticating API keys and Basic credentials. Importers can apply
+// [NewAPIKeyGuard] and [NewBasicGuard] to their routes as guard constructors. Zero-valued fields
+// use the defaults from [NewConfig].
+type Module struct {
+	// Store is the store credentials are authenticated with
+	Store CredentialStore
+
+	// CredentialsFile is the path of the file of a [FileStore] used if Store is not set
+	CredentialsFile string
+
+	// APIKeyHeader is the header API keys are read from
+	APIKeyHeader string
+
+	// APIKeyQuery is the query param API keys are read from if the header is not set
+	APIKeyQuery string
+
+	// Realm is the protection space Basic credentials are challenged for
+	Realm string
+}
+
+func (m *Module) Config() *vara.ModuleConfig {
+	return &vara.ModuleConfig{
+		Imports:              []vara.Module{&event.Module{}},
+		ExportConstructors:   []vara.ProviderConstructor{NewService},
+		ProviderConstructors: []vara.ProviderConstructor{m.newConfig, m.newStore, NewService},
+	}
+}
+
+// newConfig returns the default config overridden by the module's fields.
+func (m *Module) newConfig() Config {
+	c := NewConfig()
+	c.APIKeyHeader = cmp.Or(m.APIKeyHeader, c.APIKeyHeader)
+	c.APIKeyQuery = cmp.Or(m.APIKeyQuery, c.APIKeyQuery)
+	c.Realm = cmp.Or(m.Realm, c.Realm)
+	return c
+}
+
+// newStore returns the module's credential store, or the store of its credentials file if it has none.
+func (m *Module) newStore() (CredentialStore, error) {
+	if m.Store != nil {
+		return m.Store, nil
+	}
+	if m.CredentialsFile != "" {
+		return NewFileStore(m.CredentialsFile)
+	}
+	return nil, ErrNoCredentialStore
+}
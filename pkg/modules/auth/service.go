@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/huboh/vara/pkg/modules/event"
+)
+
+// EventFailure is the event emitted with a [Failure] when a request fails to authenticate.
+const EventFailure = "auth.failure"
+
+// Failure is the payload of the [EventFailure] event.
+type Failure struct {
+	// Scheme is the scheme the credentials were expected in
+	Scheme Scheme
+
+	// Username is the username of Basic credentials
+	Username string
+
+	// Reason is why authenticating failed, e.g. [ErrMissingCredentials] or [ErrInvalidCredentials]
+	Reason error
+
+	// RemoteAddr is the network address of the client
+	RemoteAddr string
+
+	// Path is the path of the request
+	Path string
+}
+
+type Service struct {
+	store  CredentialStore
+	config Config
+	events *event.Service
+	logger *slog.Logger
+}
+
+func NewService(c Config, s CredentialStore, e *event.Service, l *slog.Logger) *Service {
+	return &Service{
+		store:  s,
+		config: c,
+		events: e,
+		logger: l,
+	}
+}
+
+// Authenticate returns the identity of the credentials the request carries,
+// emitting an [EventFailure] event if they are not authenticated.
+func (s *Service) Authenticate(r *http.Request, c Credentials) (*Identity, error) {
+	id, err := s.store.Authenticate(r.Context(), c)
+	if err != nil {
+		s.fail(r, c, err)
+		return nil, err
+	}
+	return id, nil
+}
+
+// fail emits an [EventFailure] event for the request failing to authenticate with the credentials.
+func (s *Service) fail(r *http.Request, c Credentials, reason error) {
+	err := s.events.Emit(r.Context(), EventFailure, Failure{
+		Scheme:     c.Scheme,
+		Username:   c.Username,
+		Reason:     reason,
+		RemoteAddr: r.RemoteAddr,
+		Path:       r.URL.Path,
+	})
+	if err != nil {
+		s.logger.Warn("could not emit auth failure event", "error", err)
+	}
+}
+
+// isRejection reports if authenticating failed because of the credentials, rather than the store.
+func isRejection(err error) bool {
+	return errors.Is(err, ErrMissingCredentials) || errors.Is(err, ErrInvalidCredentials)
+}
@@ -0,0 +1,9 @@
+package authz
+
+type Config struct {
+	// RoleHierarchy maps roles to the roles they inherit, e.g. "admin" to "editor"
+	RoleHierarchy map[string][]string
+
+	// RolePermissions maps roles to the permissions they grant
+	RolePermissions map[string][]string
+}
@@ -0,0 +1,93 @@
+package authz
+
+import (
+	"github.com/huboh/vara"
+)
+
+// Guard enforces the roles, permissions and policies required by the [Roles], [Permissions]
+// and [Can] metadata of routes, and allows requests to routes requiring none. The principal of
+// every request, if any, is stored for [PrincipalFromRequest].
+//
+// Requests without a principal are rejected with a 401 Unauthorized, and requests whose
+// principal is not authorized with a 403 Forbidden. Requests for a resource that does not
+// exist are rejected with a 404 Not Found only if the policies allow the principal to act on
+// it, with a nil resource, so that existence is not disclosed to unauthorized principals. It must run after the guard authenticating
+// requests, e.g. with vara.AllOf(jwt.NewGuard, authz.NewGuard).
+type Guard struct {
+	service   *Service
+	reflector *vara.Reflector
+}
+
+// NewGuard returns a guard authorizing requests with the service. It is a [vara.GuardConstructor].
+func NewGuard(s *Service, r *vara.Reflector) *Guard {
+	return &Guard{
+		service:   s,
+		reflector: r,
+	}
+}
+
+func (g *Guard) Allow(gCtx vara.GuardContext) (bool, error) {
+	var (
+		r = gCtx.Http.R
+		c = gCtx.Context()
+
+		roles, _       = vara.GetAllAndOverride[[]string](g.reflector, c, metadataRoles)
+		permissions, _ = vara.GetAllAndOverride[[]string](g.reflector, c, metadataPermissions)
+		req, hasReq    = vara.GetAllAndOverride[requirement](g.reflector, c, metadataCan)
+	)
+
+	p, err := g.service.Principal(r)
+	if err != nil {
+		return false, err
+	}
+
+	if (len(roles) == 0) && (len(permissions) == 0) && (!hasReq) {
+		return true, nil
+	}
+	if p == nil {
+		return false, vara.ErrUnauthorized
+	}
+
+	if (len(roles) > 0) && !g.hasAnyRole(p, roles) {
+		return false, nil
+	}
+
+	for _, permission := range permissions {
+		if !g.service.HasPermission(p, permission) {
+			return false, nil
+		}
+	}
+
+	if !hasReq {
+		return true, nil
+	}
+
+	resource, loaded, err := g.service.Load(r, req.kind)
+	if err != nil {
+		return false, err
+	}
+	allowed, err := g.service.Can(r.Context(), p, req.action, req.kind, resource)
+	if (!allowed) || (err != nil) {
+		return false, err
+	}
+
+	if loaded {
+		// the policies are checked before a missing resource is reported,
+		// so principals not allowed to act on it can't tell if it exists
+		if resource == nil {
+			return false, vara.ErrNotFound
+		}
+		resourceKey.Set(c, resource)
+	}
+
+	return true, nil
+}
+
+func (g *Guard) hasAnyRole(p *Principal, roles []string) bool {
+	for _, role := range roles {
+		if g.service.HasRole(p, role) {
+			return true
+		}
+	}
+	return false
+}
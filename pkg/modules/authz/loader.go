@@ -0,0 +1,50 @@
+package authz
+
+import (
+	"net/http"
+
+	"github.com/huboh/vara"
+)
+
+// GroupLoaders is the DI group of the resource loaders of the Service.
+const GroupLoaders = "authz.loaders"
+
+// ResourceLoader loads the resources of a kind that policies are checked against.
+type ResourceLoader struct {
+	// Resource is the kind of resources the loader loads
+	Resource string
+
+	// Load returns the resource a request acts on, e.g. by the ID in its path params, or nil,
+	// or a nil pointer, if it does not exist. The request is then rejected with a 404 Not Found
+	// if the policies allow the principal to act on a nil resource, or a 403 Forbidden otherwise.
+	Load func(r *http.Request) (any, error)
+}
+
+// Loader annotates a provider constructor returning a [ResourceLoader] so the Service loads
+// the resources of its kind with it. The loader is discovered from any module of the application.
+//
+// Example:
+//
+//	ProviderConstructors: []vara.ProviderConstructor{
+//		authz.Loader(func(s *PostService) authz.ResourceLoader {
+//			return authz.ResourceLoader{
+//				Resource: "post",
+//				Load: func(r *http.Request) (any, error) {
+//					return s.find(r.Context(), vara.FromRequest(r).Param("id"))
+//				},
+//			}
+//		}),
+//	}
+func Loader(ctor vara.ProviderConstructor) vara.ProviderConstructor {
+	return vara.Provide(ctor, vara.Group(GroupLoaders), vara.Global())
+}
+
+// resourceKey is the key the resource of a request is stored under.
+var resourceKey = vara.NewKey[any]("authz.resource")
+
+// ResourceFromRequest returns the resource loaded for a request authorized by a [Guard], if any.
+func ResourceFromRequest[T any](r *http.Request) (T, bool) {
+	v, _ := resourceKey.Get(vara.FromRequest(r))
+	resource, ok := v.(T)
+	return resource, ok
+}
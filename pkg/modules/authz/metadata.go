@@ -0,0 +1,34 @@
+package authz
+
+import "github.com/huboh/vara"
+
+// metadata keys of the requirements enforced by the Guard
+const (
+	metadataRoles       = "authz.roles"
+	metadataPermissions = "authz.permissions"
+	metadataCan         = "authz.can"
+)
+
+// requirement is an action that must be allowed on resources of a kind.
+type requirement struct {
+	action string
+	kind   string
+}
+
+// Roles requires principals to have any of the roles, directly or through the role hierarchy.
+// Route metadata overrides controller metadata, which overrides module metadata.
+func Roles(roles ...string) vara.MetadataEntry {
+	return vara.SetMetadata(metadataRoles, roles)
+}
+
+// Permissions requires principals to have all of the permissions, directly or through their roles.
+// Route metadata overrides controller metadata, which overrides module metadata.
+func Permissions(permissions ...string) vara.MetadataEntry {
+	return vara.SetMetadata(metadataPermissions, permissions)
+}
+
+// Can requires the policies to allow principals to perform the action on the resource of the kind
+// the request acts on, which is loaded by the [ResourceLoader] of the kind, if any.
+func Can(action, kind string) vara.MetadataEntry {
+	return vara.SetMetadata(metadataCan, requirement{action: action, kind: kind})
+}
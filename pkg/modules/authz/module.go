@@ -0,0 +1,30 @@
+package authz
+
+import (
+	"github.com/huboh/vara"
+)
+
+// Module provides the service authorizing principals with roles, permissions and policies.
+// Importers can apply [NewGuard] to their routes as a guard constructor.
+type Module struct {
+	// RoleHierarchy maps roles to the roles they inherit, e.g. "admin" to "editor"
+	RoleHierarchy map[string][]string
+
+	// RolePermissions maps roles to the permissions they grant
+	RolePermissions map[string][]string
+}
+
+func (m *Module) Config() *vara.ModuleConfig {
+	return &vara.ModuleConfig{
+		ExportConstructors:   []vara.ProviderConstructor{NewService},
+		ProviderConstructors: []vara.ProviderConstructor{m.newConfig, NewService},
+	}
+}
+
+// newConfig returns the config of the module's fields.
+func (m *Module) newConfig() Config {
+	return Config{
+		RoleHierarchy:   m.RoleHierarchy,
+		RolePermissions: m.RolePermissions,
+	}
+}
@@ -0,0 +1,62 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/huboh/vara"
+)
+
+// GroupPolicies is the DI group of the policies the Service enforces.
+const GroupPolicies = "authz.policies"
+
+// Wildcard matches any action or resource kind of a [Policy].
+const Wildcard = "*"
+
+// PolicyFunc reports if the principal may perform the action on the resource. The resource is
+// the one loaded by the [ResourceLoader] of its kind, or nil if the kind has no loader or the
+// resource does not exist.
+type PolicyFunc func(ctx context.Context, p *Principal, action string, resource any) (bool, error)
+
+// Policy decides if principals may perform an action on resources of a kind.
+type Policy struct {
+	// Action is the action the policy applies to, or [Wildcard]
+	Action string
+
+	// Resource is the kind of resources the policy applies to, or [Wildcard]
+	Resource string
+
+	// Func decides if the principal may perform the action on the resource
+	Func PolicyFunc
+}
+
+// applies reports if the policy applies to the action on resources of the kind.
+func (p Policy) applies(action, kind string) bool {
+	return ((p.Action == action) || (p.Action == Wildcard)) &&
+		((p.Resource == kind) || (p.Resource == Wildcard))
+}
+
+// Register annotates a provider constructor returning a [Policy] so the Service enforces it.
+// The policy is discovered from any module of the application.
+//
+// Example:
+//
+//	ProviderConstructors: []vara.ProviderConstructor{
+//		authz.Register(func(s *PostService) authz.Policy {
+//			return authz.Policy{Action: "publish", Resource: "post", Func: s.canPublish}
+//		}),
+//	}
+func Register(ctor vara.ProviderConstructor) vara.ProviderConstructor {
+	return vara.Provide(ctor, vara.Group(GroupPolicies), vara.Global())
+}
+
+// Owned is implemented by resources owned by a principal.
+type Owned interface {
+	// OwnerID returns the ID of the principal owning the resource.
+	OwnerID() string
+}
+
+// IsOwner is a [PolicyFunc] allowing principals to perform the action on the [Owned] resources they own.
+func IsOwner(_ context.Context, p *Principal, _ string, resource any) (bool, error) {
+	owned, ok := resource.(Owned)
+	return ok && (p.ID != "") && (owned.OwnerID() == p.ID), nil
+}
@@ -0,0 +1,98 @@
+package authz
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/huboh/vara"
+	"github.com/huboh/vara/pkg/modules/jwt"
+)
+
+// NamePrincipalResolver is the DI name of the resolver the Service resolves principals with.
+const NamePrincipalResolver = "authz.principal_resolver"
+
+// Principal is the authenticated user or client a request is made on behalf of.
+type Principal struct {
+	// ID identifies the principal, e.g. to check it owns a resource
+	ID string
+
+	// Roles are the roles granted to the principal, before expanding the role hierarchy
+	Roles []string
+
+	// Permissions are the permissions granted to the principal, besides those of its roles
+	Permissions []string
+
+	// Attributes are other attributes of the principal policies may check
+	Attributes map[string]any
+}
+
+// PrincipalResolver resolves the principal a request is made on behalf of.
+type PrincipalResolver interface {
+	// ResolvePrincipal returns the principal of the request, or nil if the request is not authenticated.
+	ResolvePrincipal(r *http.Request) (*Principal, error)
+}
+
+// ResolvePrincipal annotates a provider constructor returning a [PrincipalResolver] so the Service
+// resolves principals with it. The resolver is discovered from any module of the application.
+//
+// Principals are resolved from the claims verified by a jwt.Guard if no resolver is provided.
+func ResolvePrincipal(ctor vara.ProviderConstructor) vara.ProviderConstructor {
+	return vara.Provide(ctor, vara.Name(NamePrincipalResolver), vara.As(new(PrincipalResolver)), vara.Global())
+}
+
+// principalKey is the key the principal of a request is stored under.
+var principalKey = vara.NewKey[*Principal]("authz.principal")
+
+// SetPrincipal stores the principal of a request in its context, e.g. in an authentication guard.
+func SetPrincipal(c *vara.Context, p *Principal) {
+	principalKey.Set(c, p)
+}
+
+// PrincipalFromRequest returns the principal of a request authorized by a [Guard], if any.
+func PrincipalFromRequest(r *http.Request) (*Principal, bool) {
+	return principalKey.Get(vara.FromRequest(r))
+}
+
+// claimsResolver resolves principals from the claims verified by a jwt.Guard: its ID from the
+// "sub" claim, its roles from the "roles" claim and its permissions from the "permissions"
+// claim, or from the space-delimited "scope" claim.
+type claimsResolver struct{}
+
+func (claimsResolver) ResolvePrincipal(r *http.Request) (*Principal, error) {
+	claims, ok := jwt.ClaimsFromRequest(r)
+	if !ok {
+		return nil, nil
+	}
+
+	p := &Principal{
+		ID:          claims.Subject(),
+		Roles:       getStrings(claims["roles"]),
+		Permissions: getStrings(claims["permissions"]),
+		Attributes:  claims,
+	}
+	if scope, ok := claims["scope"].(string); ok && (len(p.Permissions) == 0) {
+		p.Permissions = strings.Fields(scope)
+	}
+
+	return p, nil
+}
+
+// getStrings returns the strings of a claim holding either a string or a list of strings.
+func getStrings(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		strs := make([]string, 0, len(v))
+		for _, s := range v {
+			if s, ok := s.(string); ok {
+				strs = append(strs, s)
+			}
+		}
+		return strs
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,189 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+
+	"github.com/huboh/vara"
+)
+
+var (
+	// ErrInvalidPolicy indicates a policy has no function
+	ErrInvalidPolicy = errors.New("invalid policy")
+
+	// ErrInvalidLoader indicates a resource loader has no function, or loads a kind another loader loads
+	ErrInvalidLoader = errors.New("invalid resource loader")
+)
+
+// ServiceInput holds the dependencies injected into the Service.
+type ServiceInput struct {
+	vara.In
+
+	Config   Config
+	Policies []Policy          `group:"authz.policies"`
+	Loaders  []ResourceLoader  `group:"authz.loaders"`
+	Resolver PrincipalResolver `name:"authz.principal_resolver" optional:"true"`
+}
+
+type Service struct {
+	config   Config
+	policies []Policy
+	loaders  map[string]ResourceLoader
+	resolver PrincipalResolver
+}
+
+func NewService(in ServiceInput) (*Service, error) {
+	s := &Service{
+		config:   in.Config,
+		loaders:  make(map[string]ResourceLoader, len(in.Loaders)),
+		resolver: in.Resolver,
+	}
+	if s.resolver == nil {
+		s.resolver = claimsResolver{}
+	}
+
+	for _, p := range in.Policies {
+		if p.Func == nil {
+			return nil, fmt.Errorf("%w: %s %s", ErrInvalidPolicy, p.Action, p.Resource)
+		}
+		s.policies = append(s.policies, p)
+	}
+
+	for _, l := range in.Loaders {
+		_, exists := s.loaders[l.Resource]
+		if (l.Load == nil) || exists {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidLoader, l.Resource)
+		}
+		s.loaders[l.Resource] = l
+	}
+
+	return s, nil
+}
+
+// Principal returns the principal of the request stored with [SetPrincipal], or resolves
+// and stores it with the principal resolver. It returns nil if the request is not authenticated.
+func (s *Service) Principal(r *http.Request) (*Principal, error) {
+	if p, ok := PrincipalFromRequest(r); ok {
+		return p, nil
+	}
+
+	p, err := s.resolver.ResolvePrincipal(r)
+	if (err != nil) || (p == nil) {
+		return nil, err
+	}
+
+	SetPrincipal(vara.FromRequest(r), p)
+	return p, nil
+}
+
+// Roles returns the roles of the principal, including the roles they inherit through the role hierarchy.
+func (s *Service) Roles(p *Principal) []string {
+	var (
+		roles   []string
+		visited = make(map[string]bool)
+		visit   func(role string)
+	)
+
+	visit = func(role string) {
+		if visited[role] {
+			return
+		}
+		visited[role] = true
+		roles = append(roles, role)
+
+		for _, inherited := range s.config.RoleHierarchy[role] {
+			visit(inherited)
+		}
+	}
+
+	if p != nil {
+		for _, role := range p.Roles {
+			visit(role)
+		}
+	}
+
+	return roles
+}
+
+// HasRole reports if the principal has the role, directly or through the role hierarchy.
+func (s *Service) HasRole(p *Principal, role string) bool {
+	return slices.Contains(s.Roles(p), role)
+}
+
+// HasPermission reports if the principal has the permission, directly or through its roles.
+func (s *Service) HasPermission(p *Principal, permission string) bool {
+	if p == nil {
+		return false
+	}
+	if slices.Contains(p.Permissions, permission) {
+		return true
+	}
+
+	for _, role := range s.Roles(p) {
+		if slices.Contains(s.config.RolePermissions[role], permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// Can reports if any policy applying to the action on resources of the kind allows the principal
+// to perform it on the resource. Actions no policy applies to are denied.
+func (s *Service) Can(ctx context.Context, p *Principal, action, kind string, resource any) (bool, error) {
+	if p == nil {
+		return false, nil
+	}
+
+	for _, policy := range s.policies {
+		if !policy.applies(action, kind) {
+			continue
+		}
+
+		allowed, err := policy.Func(ctx, p, action, resource)
+		if err != nil {
+			return false, err
+		}
+		if allowed {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Load returns the resource of the kind the request acts on, loaded by the resource loader of the
+// kind, and reports if the kind has a loader. The resource is nil if it does not exist, including
+// when the loader returns a nil pointer, map, slice or other nil value of a concrete type.
+func (s *Service) Load(r *http.Request, kind string) (any, bool, error) {
+	l, ok := s.loaders[kind]
+	if !ok {
+		return nil, false, nil
+	}
+
+	resource, err := l.Load(r)
+	if err != nil {
+		return nil, true, fmt.Errorf("could not load %s: %w", kind, err)
+	}
+	if isNil(resource) {
+		return nil, true, nil
+	}
+
+	return resource, true, nil
+}
+
+// isNil reports if v is nil, or a nil value of a concrete type stored in an interface.
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice:
+		return rv.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package event
+
+import (
+	"context"
+
+	"github.com/huboh/vara"
+)
+
+// GroupObservers is the DI group of observers notified by the event Service.
+const GroupObservers = "event.observers"
+
+// Observer observes the events emitted and handled by the Service, e.g. to record
+// metrics or traces.
+type Observer interface {
+	// ObserveEmit is called when an event is emitted. The returned context replaces the
+	// event's context, and done is called with the result once the event was emitted.
+	ObserveEmit(ctx context.Context, evt string) (c context.Context, done func(error))
+
+	// ObserveListener is called before a listener handles an event. The returned context
+	// replaces the event's context for the listener, and done is called with the
+	// listener's result.
+	ObserveListener(ctx context.Context, evt string) (c context.Context, done func(error))
+}
+
+// Observers holds the observers injected into the Service.
+type Observers struct {
+	vara.In
+
+	Observers []Observer `group:"event.observers"`
+}
+
+// Observe annotates a provider constructor returning an [Observer] so it is notified by
+// the event Service. The observer is discovered from any module of the application.
+func Observe(ctor vara.ProviderConstructor) vara.ProviderConstructor {
+	return vara.Provide(ctor, vara.Group(GroupObservers), vara.As(new(Observer)), vara.Global())
+}
+
+// observe notifies the observers using fn, chaining the contexts they return.
+// The returned done function notifies the observers in reverse order.
+func observe(ctx context.Context, observers []Observer, fn func(Observer, context.Context) (context.Context, func(error))) (context.Context, func(error)) {
+	dones := make([]func(error), 0, len(observers))
+	for _, o := range observers {
+		var done func(error)
+		ctx, done = fn(o, ctx)
+		dones = append(dones, done)
+	}
+
+	return ctx, func(err error) {
+		for i := len(dones) - 1; i >= 0; i-- {
+			if dones[i] != nil {
+				dones[i](err)
+			}
+		}
+	}
+}
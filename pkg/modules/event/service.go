@@ -18,18 +18,25 @@ var (
 type Service struct {
 	config    Config
 	mu        sync.RWMutex
+	observers []Observer
 	listeners map[string][]*Listener
 }
 
-func NewService(cfg Config) *Service {
+func NewService(cfg Config, o Observers) *Service {
 	return &Service{
 		config:    cfg,
+		observers: o.Observers,
 		listeners: make(map[string][]*Listener),
 	}
 }
 
 // Emit emits an event to all registered listeners
-func (s *Service) Emit(ctx context.Context, evt string, payload any) error {
+func (s *Service) Emit(ctx context.Context, evt string, payload any) (err error) {
+	ctx, done := observe(ctx, s.observers, func(o Observer, ctx context.Context) (context.Context, func(error)) {
+		return o.ObserveEmit(ctx, evt)
+	})
+	defer func() { done(err) }()
+
 	var (
 		wg    = sync.WaitGroup{}
 		errs  = make(chan error, len(s.listeners[evt]))
@@ -160,7 +167,20 @@ func (s *Service) RemoveListener(ltn *Listener) error {
 
 // processSyncListener handles synchronous event processing
 func (s *Service) processSyncListener(ltn *Listener, evt Event) error {
-	return ltn.Func(evt)
+	return s.callListener(ltn, evt)
+}
+
+// callListener calls the listener function, notifying the observers
+func (s *Service) callListener(ltn *Listener, evt Event) error {
+	ctx, done := observe(evt.Metadata.Context, s.observers, func(o Observer, ctx context.Context) (context.Context, func(error)) {
+		return o.ObserveListener(ctx, ltn.Event)
+	})
+
+	evt.Metadata.Context = ctx
+	err := ltn.Func(evt)
+	done(err)
+
+	return err
 }
 
 // processAsyncListener handles async event processing
@@ -170,7 +190,7 @@ func (s *Service) processAsyncListener(ltn *Listener, evt Event) error {
 	timeoutCh := time.After(s.config.AsyncTimeout)
 
 	go func() {
-		errCh <- ltn.Func(evt)
+		errCh <- s.callListener(ltn, evt)
 	}()
 
 	select {
@@ -0,0 +1,28 @@
+package health
+
+import "time"
+
+type Config struct {
+	// LivenessPath is the path of the liveness endpoint
+	LivenessPath string
+
+	// ReadinessPath is the path of the readiness endpoint
+	ReadinessPath string
+
+	// CheckTimeout is the default timeout for each indicator check
+	CheckTimeout time.Duration
+
+	// DrainDelay is how long shutdown is delayed after readiness starts failing,
+	// giving load balancers time to stop routing traffic to the application. It is
+	// not taken from the shutdown timeout, see vara.WithShutdownTimeout
+	DrainDelay time.Duration
+}
+
+func NewConfig() Config {
+	return Config{
+		LivenessPath:  "/healthz",
+		ReadinessPath: "/readyz",
+		CheckTimeout:  5 * time.Second,
+		DrainDelay:    0,
+	}
+}
@@ -0,0 +1,59 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/huboh/vara"
+	"github.com/huboh/vara/pkg/modules/json"
+)
+
+type controller struct {
+	config Config
+	health *Service
+	json   *json.Service
+}
+
+func newController(c Config, s *Service, j *json.Service) *controller {
+	return &controller{
+		config: c,
+		health: s,
+		json:   j,
+	}
+}
+
+func (c *controller) Config() *vara.ControllerConfig {
+	return &vara.ControllerConfig{
+		RouteConfigs: []*vara.RouteConfig{
+			{
+				Pattern: c.config.LivenessPath,
+				Method:  http.MethodGet,
+				Handler: http.HandlerFunc(c.handleLiveness),
+			},
+			{
+				Pattern: c.config.ReadinessPath,
+				Method:  http.MethodGet,
+				Handler: http.HandlerFunc(c.handleReadiness),
+			},
+		},
+	}
+}
+
+func (c *controller) handleLiveness(w http.ResponseWriter, r *http.Request) {
+	c.write(w, c.health.Liveness(r.Context()))
+}
+
+func (c *controller) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	c.write(w, c.health.Readiness(r.Context()))
+}
+
+func (c *controller) write(w http.ResponseWriter, report Report) {
+	status := http.StatusOK
+	if report.Status == StatusDown {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.json.Write(w, json.Response{
+		Data:       report,
+		StatusCode: status,
+	})
+}
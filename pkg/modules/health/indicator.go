@@ -0,0 +1,80 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"github.com/huboh/vara"
+)
+
+// recognized indicator groups
+const (
+	// GroupLiveness is the DI group of indicators checked by the liveness endpoint.
+	GroupLiveness = "health.liveness"
+
+	// GroupReadiness is the DI group of indicators checked by the readiness endpoint.
+	GroupReadiness = "health.readiness"
+)
+
+// Indicator reports the health of a single dependency of the application,
+// e.g. a database connection or an event bus backlog.
+type Indicator interface {
+	// Name identifies the indicator in health reports.
+	Name() string
+
+	// Check returns an error if the dependency is unhealthy.
+	Check(ctx context.Context) error
+}
+
+// indicatorTimeout is implemented by indicators that override the
+// check timeout configured for the health module.
+type indicatorTimeout interface {
+	Timeout() time.Duration
+}
+
+// indicator is an Indicator backed by a function.
+type indicator struct {
+	name    string
+	check   func(context.Context) error
+	timeout time.Duration
+}
+
+// NewIndicator returns an Indicator that calls check. If timeout is greater than zero,
+// it overrides the check timeout configured for the health module.
+func NewIndicator(name string, timeout time.Duration, check func(context.Context) error) Indicator {
+	return &indicator{
+		name:    name,
+		check:   check,
+		timeout: timeout,
+	}
+}
+
+func (i *indicator) Name() string { return i.name }
+
+func (i *indicator) Timeout() time.Duration { return i.timeout }
+
+func (i *indicator) Check(ctx context.Context) error { return i.check(ctx) }
+
+// Liveness annotates a provider constructor returning an [Indicator] so it is checked by the
+// liveness endpoint. The indicator is discovered from any module of the application.
+//
+// Liveness indicators should only fail when the application can't recover without a restart.
+func Liveness(ctor vara.ProviderConstructor) vara.ProviderConstructor {
+	return vara.Provide(ctor, vara.Group(GroupLiveness), vara.As(new(Indicator)), vara.Global())
+}
+
+// Readiness annotates a provider constructor returning an [Indicator] so it is checked by the
+// readiness endpoint. The indicator is discovered from any module of the application.
+//
+// Example:
+//
+//	func newDatabaseIndicator(db *database.Service) health.Indicator {
+//		return health.NewIndicator("database", time.Second, db.Ping)
+//	}
+//
+//	ProviderConstructors: []vara.ProviderConstructor{
+//		health.Readiness(newDatabaseIndicator),
+//	}
+func Readiness(ctor vara.ProviderConstructor) vara.ProviderConstructor {
+	return vara.Provide(ctor, vara.Group(GroupReadiness), vara.As(new(Indicator)), vara.Global())
+}
@@ -0,0 +1,43 @@
+package health
+
+import (
+	"cmp"
+	"time"
+
+	"github.com/huboh/vara"
+	"github.com/huboh/vara/pkg/modules/json"
+)
+
+// Module exposes liveness and readiness endpoints. Zero-valued fields use the defaults from [NewConfig].
+type Module struct {
+	// LivenessPath is the path of the liveness endpoint
+	LivenessPath string
+
+	// ReadinessPath is the path of the readiness endpoint
+	ReadinessPath string
+
+	// CheckTimeout is the default timeout for each indicator check
+	CheckTimeout time.Duration
+
+	// DrainDelay is how long shutdown is delayed after readiness starts failing
+	DrainDelay time.Duration
+}
+
+func (m *Module) Config() *vara.ModuleConfig {
+	return &vara.ModuleConfig{
+		Imports:                []vara.Module{&json.Module{}},
+		ExportConstructors:     []vara.ProviderConstructor{NewService},
+		ProviderConstructors:   []vara.ProviderConstructor{m.newConfig, NewService},
+		ControllerConstructors: []vara.ControllerConstructor{newController},
+	}
+}
+
+// newConfig returns the default config overridden by the module's fields.
+func (m *Module) newConfig() Config {
+	c := NewConfig()
+	c.LivenessPath = cmp.Or(m.LivenessPath, c.LivenessPath)
+	c.ReadinessPath = cmp.Or(m.ReadinessPath, c.ReadinessPath)
+	c.CheckTimeout = cmp.Or(m.CheckTimeout, c.CheckTimeout)
+	c.DrainDelay = cmp.Or(m.DrainDelay, c.DrainDelay)
+	return c
+}
@@ -0,0 +1,33 @@
+package health
+
+import "time"
+
+// Status is the health status of an indicator or the application.
+type Status string
+
+// recognized health Status
+const (
+	StatusUp   = Status("up")
+	StatusDown = Status("down")
+)
+
+// Report is the aggregated result of the checks run by a health endpoint.
+type Report struct {
+	// Status is "down" if any check failed, "up" otherwise.
+	Status Status `json:"status"`
+
+	// Checks holds the result of each check by the indicator name.
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// CheckResult is the result of a single indicator check.
+type CheckResult struct {
+	// Status is the indicator's status.
+	Status Status `json:"status"`
+
+	// Error is the reason the check failed. this field is omitted if the check passed.
+	Error string `json:"error,omitempty"`
+
+	// Duration is how long the check took.
+	Duration time.Duration `json:"duration"`
+}
@@ -0,0 +1,163 @@
+// Package health provides liveness and readiness endpoints aggregating
+// the health of the application's dependencies.
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/huboh/vara"
+)
+
+var (
+	// ErrShuttingDown indicates the application is shutting down and no longer ready
+	ErrShuttingDown = errors.New("application is shutting down")
+)
+
+// shutdownIndicatorName is the name of the check reporting whether the application is shutting down.
+const shutdownIndicatorName = "shutdown"
+
+// ServiceParams holds the dependencies of the health Service.
+type ServiceParams struct {
+	vara.In
+
+	Config    Config
+	Lifecycle *vara.Lifecycle
+	Liveness  []Indicator `group:"health.liveness"`
+	Readiness []Indicator `group:"health.readiness"`
+}
+
+// Service checks the health of the application using the registered indicators.
+type Service struct {
+	config    Config
+	draining  atomic.Bool
+	liveness  []Indicator
+	readiness []Indicator
+}
+
+func NewService(p ServiceParams) *Service {
+	s := &Service{
+		config:    p.Config,
+		liveness:  p.Liveness,
+		readiness: p.Readiness,
+	}
+
+	p.Lifecycle.Append(vara.LifecycleHook{
+		Name:      "health",
+		OnPreStop: s.drain,
+	})
+
+	return s
+}
+
+// Liveness runs the liveness checks and reports whether the application is alive.
+func (s *Service) Liveness(ctx context.Context) Report {
+	return s.check(ctx, s.liveness)
+}
+
+// Readiness runs the readiness checks and reports whether the application can serve traffic.
+// The application is reported as not ready once it starts shutting down.
+func (s *Service) Readiness(ctx context.Context) Report {
+	report := s.check(ctx, s.readiness)
+	if s.draining.Load() {
+		report.Status = StatusDown
+		report.Checks[shutdownIndicatorName] = CheckResult{
+			Status: StatusDown,
+			Error:  ErrShuttingDown.Error(),
+		}
+	}
+	return report
+}
+
+// drain marks the application as not ready, then waits for the configured drain delay.
+func (s *Service) drain(ctx context.Context) error {
+	s.draining.Store(true)
+
+	if s.config.DrainDelay <= 0 {
+		return nil
+	}
+
+	select {
+	case <-time.After(s.config.DrainDelay):
+		return nil
+
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// check runs the indicators concurrently and aggregates their results.
+func (s *Service) check(ctx context.Context, indicators []Indicator) Report {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		report = Report{
+			Status: StatusUp,
+			Checks: make(map[string]CheckResult, len(indicators)),
+		}
+	)
+
+	for _, ind := range indicators {
+		wg.Add(1)
+		go func(ind Indicator) {
+			defer wg.Done()
+
+			res := s.runCheck(ctx, ind)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if res.Status == StatusDown {
+				report.Status = StatusDown
+			}
+			report.Checks[ind.Name()] = res
+		}(ind)
+	}
+
+	wg.Wait()
+	return report
+}
+
+// runCheck runs a single indicator check, enforcing its timeout.
+func (s *Service) runCheck(ctx context.Context, ind Indicator) CheckResult {
+	var (
+		start   = time.Now()
+		errCh   = make(chan error, 1)
+		timeout = s.config.CheckTimeout
+	)
+
+	if t, ok := ind.(indicatorTimeout); ok && (t.Timeout() > 0) {
+		timeout = t.Timeout()
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	go func() {
+		errCh <- ind.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	res := CheckResult{
+		Status:   StatusUp,
+		Duration: time.Since(start),
+	}
+	if err != nil {
+		res.Status = StatusDown
+		res.Error = err.Error()
+	}
+
+	return res
+}
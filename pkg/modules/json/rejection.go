@@ -0,0 +1,32 @@
+package json
+
+import (
+	"net/http"
+
+	"github.com/huboh/vara"
+)
+
+// RejectionRenderer writes the responses of requests rejected by guards as a JSON [Response].
+//
+// It is registered with [vara.GlobalRejectionRenderer]:
+//
+//	ProviderConstructors: []vara.ProviderConstructor{
+//		vara.GlobalRejectionRenderer(json.NewRejectionRenderer),
+//	}
+type RejectionRenderer struct {
+	json *Service
+}
+
+func NewRejectionRenderer(s *Service) *RejectionRenderer {
+	return &RejectionRenderer{
+		json: s,
+	}
+}
+
+func (r *RejectionRenderer) RenderRejection(w http.ResponseWriter, _ *http.Request, err *vara.HTTPError) {
+	r.json.Write(w, Response{
+		Message:    err.Message,
+		StatusCode: err.Status,
+		Error:      NewError(http.StatusText(err.Status), err.Message, "", ""),
+	})
+}
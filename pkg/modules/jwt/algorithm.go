@@ -0,0 +1,108 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+// Algorithm is the algorithm a token is signed with, as named by its "alg" header.
+type Algorithm string
+
+// supported Algorithm
+const (
+	HS256 = Algorithm("HS256")
+	RS256 = Algorithm("RS256")
+	ES256 = Algorithm("ES256")
+	EdDSA = Algorithm("EdDSA")
+)
+
+// es256KeySize is the size of the r and s values of an ES256 signature.
+const es256KeySize = 32
+
+// sign returns the signature of the signing input made with the key.
+func (a Algorithm) sign(k Key, input []byte) ([]byte, error) {
+	digest := sha256.Sum256(input)
+
+	switch a {
+	case HS256:
+		if len(k.Secret) == 0 {
+			return nil, fmt.Errorf("%w: key (%s) has no secret", ErrInvalidKey, k.ID)
+		}
+		mac := hmac.New(sha256.New, k.Secret)
+		mac.Write(input)
+		return mac.Sum(nil), nil
+
+	case RS256:
+		priv, ok := k.PrivateKey.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: key (%s) has no RSA private key", ErrInvalidKey, k.ID)
+		}
+		return rsa.SignPKCS1v15(rand.Reader, priv, crypto.SHA256, digest[:])
+
+	case ES256:
+		priv, ok := k.PrivateKey.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: key (%s) has no ECDSA private key", ErrInvalidKey, k.ID)
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, priv, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		sig := make([]byte, (es256KeySize * 2))
+		r.FillBytes(sig[:es256KeySize])
+		s.FillBytes(sig[es256KeySize:])
+		return sig, nil
+
+	case EdDSA:
+		priv, ok := k.PrivateKey.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: key (%s) has no Ed25519 private key", ErrInvalidKey, k.ID)
+		}
+		return ed25519.Sign(priv, input), nil
+
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, a)
+	}
+}
+
+// verify reports if the signature of the signing input was made with the key.
+func (a Algorithm) verify(k Key, input, sig []byte) bool {
+	digest := sha256.Sum256(input)
+
+	switch a {
+	case HS256:
+		if len(k.Secret) == 0 {
+			return false
+		}
+		mac := hmac.New(sha256.New, k.Secret)
+		mac.Write(input)
+		return hmac.Equal(sig, mac.Sum(nil))
+
+	case RS256:
+		pub, ok := k.PublicKey.(*rsa.PublicKey)
+		return ok && (rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) == nil)
+
+	case ES256:
+		pub, ok := k.PublicKey.(*ecdsa.PublicKey)
+		if !ok || (len(sig) != (es256KeySize * 2)) {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:es256KeySize])
+		s := new(big.Int).SetBytes(sig[es256KeySize:])
+		return ecdsa.Verify(pub, digest[:], r, s)
+
+	case EdDSA:
+		pub, ok := k.PublicKey.(ed25519.PublicKey)
+		return ok && (len(pub) == ed25519.PublicKeySize) && ed25519.Verify(pub, input, sig)
+
+	default:
+		return false
+	}
+}
@@ -0,0 +1,111 @@
+package jwt
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+)
+
+// registered claim names
+const (
+	ClaimIssuer    = "iss"
+	ClaimSubject   = "sub"
+	ClaimAudience  = "aud"
+	ClaimExpiresAt = "exp"
+	ClaimNotBefore = "nbf"
+	ClaimIssuedAt  = "iat"
+	ClaimID        = "jti"
+)
+
+// Claims are the claims of a token, keyed by their names. Numbers of verified
+// tokens are decoded as [json.Number].
+type Claims map[string]any
+
+// Issuer returns the "iss" claim.
+func (c Claims) Issuer() string { return c.string(ClaimIssuer) }
+
+// Subject returns the "sub" claim.
+func (c Claims) Subject() string { return c.string(ClaimSubject) }
+
+// ID returns the "jti" claim.
+func (c Claims) ID() string { return c.string(ClaimID) }
+
+// Audience returns the "aud" claim, which is either a single string or a list of strings.
+func (c Claims) Audience() []string {
+	switch aud := c[ClaimAudience].(type) {
+	case string:
+		return []string{aud}
+	case []string:
+		return aud
+	case []any:
+		auds := make([]string, 0, len(aud))
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				auds = append(auds, s)
+			}
+		}
+		return auds
+	default:
+		return nil
+	}
+}
+
+// ExpiresAt returns the "exp" claim, and reports if the claim is set.
+func (c Claims) ExpiresAt() (time.Time, bool) { return c.time(ClaimExpiresAt) }
+
+// NotBefore returns the "nbf" claim, and reports if the claim is set.
+func (c Claims) NotBefore() (time.Time, bool) { return c.time(ClaimNotBefore) }
+
+// IssuedAt returns the "iat" claim, and reports if the claim is set.
+func (c Claims) IssuedAt() (time.Time, bool) { return c.time(ClaimIssuedAt) }
+
+func (c Claims) string(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// time returns the claim holding the number of seconds since the Unix epoch.
+func (c Claims) time(name string) (time.Time, bool) {
+	var secs float64
+
+	switch v := c[name].(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		secs = f
+	case float64:
+		secs = v
+	case int64:
+		secs = float64(v)
+	case int:
+		secs = float64(v)
+	case time.Time:
+		return v, true
+	default:
+		return time.Time{}, false
+	}
+
+	whole, frac := math.Modf(secs)
+	return time.Unix(int64(whole), int64(frac*1e9)), true
+}
+
+// hasAudience reports if the "aud" claim contains the audience.
+func (c Claims) hasAudience(audience string) bool {
+	for _, aud := range c.Audience() {
+		if aud == audience {
+			return true
+		}
+	}
+	return false
+}
+
+// clone returns a shallow copy of the claims.
+func (c Claims) clone() Claims {
+	cloned := make(Claims, len(c))
+	for name, value := range c {
+		cloned[name] = value
+	}
+	return cloned
+}
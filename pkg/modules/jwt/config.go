@@ -0,0 +1,101 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/huboh/vara/pkg/modules/config"
+)
+
+// env keys the Config is read from
+const (
+	EnvIssuer   = "JWT_ISSUER"
+	EnvAudience = "JWT_AUDIENCE"
+	EnvTTL      = "JWT_TTL"
+	EnvLeeway   = "JWT_LEEWAY"
+	EnvSecret   = "JWT_SECRET"
+	EnvJWKSFile = "JWT_JWKS_FILE"
+
+	EnvAllowMissingExpiry = "JWT_ALLOW_MISSING_EXPIRY"
+)
+
+var (
+	// ErrNoKeys indicates neither a JWKS file nor a secret is configured
+	ErrNoKeys = errors.New("no JWT keys configured")
+)
+
+type Config struct {
+	// Issuer is the "iss" claim of signed tokens, and the issuer verified tokens must have
+	Issuer string
+
+	// Audience is the audience verified tokens must be intended for, if set
+	Audience string
+
+	// TTL is how long signed tokens are valid for, unless their claims set an expiry
+	TTL time.Duration
+
+	// Leeway is the clock skew tolerated when validating the time claims of tokens
+	Leeway time.Duration
+
+	// Secret is the HS256 secret tokens are signed and verified with, if no JWKS file is set
+	Secret []byte
+
+	// JWKSFile is the path of the JSON Web Key Set file tokens are signed and verified with
+	JWKSFile string
+
+	// AllowMissingExpiry accepts verified tokens without an "exp" claim, which never expire.
+	// They are rejected by default
+	AllowMissingExpiry bool
+}
+
+// NewConfig returns the JWT config read from the JWT_ISSUER, JWT_AUDIENCE, JWT_TTL, JWT_LEEWAY,
+// JWT_SECRET, JWT_JWKS_FILE and JWT_ALLOW_MISSING_EXPIRY env keys. Tokens are valid for an hour by default.
+func NewConfig(c *config.Service) (Config, error) {
+	cfg := Config{
+		Issuer:   c.Get(EnvIssuer),
+		Audience: c.Get(EnvAudience),
+		TTL:      time.Hour,
+		Secret:   []byte(c.Get(EnvSecret)),
+		JWKSFile: c.Get(EnvJWKSFile),
+	}
+
+	for key, dst := range map[string]*time.Duration{EnvTTL: &cfg.TTL, EnvLeeway: &cfg.Leeway} {
+		if val, ok := c.Lookup(key); ok {
+			d, err := time.ParseDuration(val)
+			if err != nil {
+				return Config{}, fmt.Errorf("%s: %w", key, err)
+			}
+			*dst = d
+		}
+	}
+
+	if val, ok := c.Lookup(EnvAllowMissingExpiry); ok {
+		allow, err := strconv.ParseBool(val)
+		if err != nil {
+			return Config{}, fmt.Errorf("%s: %w", EnvAllowMissingExpiry, err)
+		}
+		cfg.AllowMissingExpiry = allow
+	}
+
+	return cfg, nil
+}
+
+// NewKeySource returns the key source of the config's JWKS file, or of its secret if it has none.
+func NewKeySource(c Config) (KeySource, error) {
+	if c.JWKSFile != "" {
+		return NewJWKSFile(c.JWKSFile)
+	}
+
+	if len(c.Secret) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	k, err := NewKey("", HS256, c.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKeySet(k), nil
+}
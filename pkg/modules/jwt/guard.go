@@ -0,0 +1,59 @@
+package jwt
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/huboh/vara"
+)
+
+// claimsKey is the key the claims of a request's verified token are stored under.
+var claimsKey = vara.NewKey[Claims]("jwt.claims")
+
+// ClaimsFromRequest returns the claims of the token verified by a [Guard] for the request, if any.
+func ClaimsFromRequest(r *http.Request) (Claims, bool) {
+	return claimsKey.Get(vara.FromRequest(r))
+}
+
+// Guard allows requests carrying a valid Bearer token in their Authorization header, storing the
+// token's claims in the request's [vara.Context] for [ClaimsFromRequest]. Other requests are
+// rejected with a 401 Unauthorized challenging the client to authenticate.
+type Guard struct {
+	service *Service
+}
+
+// NewGuard returns a guard verifying tokens with the service. It is a [vara.GuardConstructor],
+// and can be applied to every route with [vara.GlobalGuard].
+func NewGuard(s *Service) *Guard {
+	return &Guard{
+		service: s,
+	}
+}
+
+func (g *Guard) Allow(gCtx vara.GuardContext) (bool, error) {
+	token, ok := bearerToken(gCtx.Http.R)
+	if !ok {
+		return false, vara.ErrUnauthorized.WithHeader("WWW-Authenticate", "Bearer")
+	}
+
+	claims, err := g.service.Verify(token)
+	if err != nil {
+		return false, vara.ErrUnauthorized.
+			WithHeader("WWW-Authenticate", `Bearer error="invalid_token"`).
+			Wrap(err)
+	}
+
+	claimsKey.Set(gCtx.Context(), claims)
+	return true, nil
+}
+
+// bearerToken returns the Bearer token of the request's Authorization header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, (token != "")
+}
@@ -0,0 +1,343 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// jwk is a JSON Web Key, as defined by RFC 7517.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+
+	// oct
+	K string `json:"k"`
+
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	P string `json:"p"`
+	Q string `json:"q"`
+
+	// EC and OKP
+	X string `json:"x"`
+	Y string `json:"y"`
+
+	// RSA, EC and OKP private keys
+	D string `json:"d"`
+}
+
+// ParseJWKS returns the keys of a JSON Web Key Set, as defined by RFC 7517. It supports
+// "oct", "RSA", P-256 "EC" and Ed25519 "OKP" keys, and skips keys meant for encryption.
+func ParseJWKS(data []byte) ([]Key, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	err := json.Unmarshal(data, &set)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+
+	keys := make([]Key, 0, len(set.Keys))
+	for i, j := range set.Keys {
+		if j.Use == "enc" {
+			continue
+		}
+
+		k, err := j.key()
+		if err != nil {
+			return nil, fmt.Errorf("key %d (%s): %w", i, j.Kid, err)
+		}
+		keys = append(keys, k)
+	}
+
+	return keys, nil
+}
+
+// key returns the key the JWK holds.
+func (j jwk) key() (Key, error) {
+	var (
+		alg      Algorithm
+		material any
+		err      error
+	)
+
+	switch j.Kty {
+	case "oct":
+		alg = HS256
+		material, err = decodeSegment(j.K)
+
+	case "RSA":
+		alg = RS256
+		material, err = j.rsaKey()
+
+	case "EC":
+		alg = ES256
+		material, err = j.ecdsaKey()
+
+	case "OKP":
+		alg = EdDSA
+		material, err = j.ed25519Key()
+
+	default:
+		return Key{}, fmt.Errorf("%w: unsupported key type %q", ErrInvalidKey, j.Kty)
+	}
+	if err != nil {
+		return Key{}, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+
+	if (j.Alg != "") && (Algorithm(j.Alg) != alg) {
+		return Key{}, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, j.Alg)
+	}
+
+	return NewKey(j.Kid, alg, material)
+}
+
+func (j jwk) rsaKey() (any, error) {
+	n, err := decodeBigInt(j.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := decodeBigInt(j.E)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsInt64() || (e.Int64() > (1<<31 - 1)) {
+		return nil, fmt.Errorf("invalid RSA exponent")
+	}
+
+	pub := &rsa.PublicKey{N: n, E: int(e.Int64())}
+	if j.D == "" {
+		return pub, nil
+	}
+
+	var d, p, q *big.Int
+	for _, v := range []struct {
+		dst **big.Int
+		src string
+	}{{&d, j.D}, {&p, j.P}, {&q, j.Q}} {
+		*v.dst, err = decodeBigInt(v.src)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	priv := &rsa.PrivateKey{PublicKey: *pub, D: d, Primes: []*big.Int{p, q}}
+	err = priv.Validate()
+	if err != nil {
+		return nil, err
+	}
+	priv.Precompute()
+
+	return priv, nil
+}
+
+func (j jwk) ecdsaKey() (any, error) {
+	if j.Crv != "P-256" {
+		return nil, fmt.Errorf("unsupported curve %q", j.Crv)
+	}
+
+	x, err := decodeSegment(j.X)
+	if err != nil {
+		return nil, err
+	}
+
+	y, err := decodeSegment(j.Y)
+	if err != nil {
+		return nil, err
+	}
+	if (len(x) != es256KeySize) || (len(y) != es256KeySize) {
+		return nil, fmt.Errorf("invalid P-256 point")
+	}
+
+	// validates the point is on the curve
+	point := append(append([]byte{4}, x...), y...)
+	_, err = ecdh.P256().NewPublicKey(point)
+	if err != nil {
+		return nil, err
+	}
+
+	pub := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}
+	if j.D == "" {
+		return pub, nil
+	}
+
+	d, err := decodeSegment(j.D)
+	if err != nil {
+		return nil, err
+	}
+
+	priv, err := ecdh.P256().NewPrivateKey(d)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(priv.PublicKey().Bytes(), point) {
+		return nil, fmt.Errorf("private key does not match public key")
+	}
+
+	return &ecdsa.PrivateKey{PublicKey: *pub, D: new(big.Int).SetBytes(d)}, nil
+}
+
+func (j jwk) ed25519Key() (any, error) {
+	if j.Crv != "Ed25519" {
+		return nil, fmt.Errorf("unsupported curve %q", j.Crv)
+	}
+
+	x, err := decodeSegment(j.X)
+	if err != nil {
+		return nil, err
+	}
+	if len(x) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 public key")
+	}
+	if j.D == "" {
+		return ed25519.PublicKey(x), nil
+	}
+
+	d, err := decodeSegment(j.D)
+	if err != nil {
+		return nil, err
+	}
+	if len(d) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid Ed25519 private key")
+	}
+
+	priv := ed25519.NewKeyFromSeed(d)
+	if !bytes.Equal(priv.Public().(ed25519.PublicKey), x) {
+		return nil, fmt.Errorf("private key does not match public key")
+	}
+
+	return priv, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := decodeSegment(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("missing key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func decodeSegment(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+// jwksCheckInterval is how often a [JWKSFile] checks if its file was modified.
+const jwksCheckInterval = time.Second
+
+// JWKSFile is a [KeySource] reading its keys from a JSON Web Key Set file, which
+// is read again once modified so keys are rotated without restarting the application.
+// The file is checked for modifications at most once per second.
+// The first key of the file that can sign tokens is the signing key.
+type JWKSFile struct {
+	path    string
+	now     func() time.Time
+	mu      sync.RWMutex
+	keys    []Key
+	modTime time.Time
+
+	// checkedAt is the Unix time in nanoseconds the file was last checked for modifications
+	checkedAt atomic.Int64
+}
+
+// NewJWKSFile returns the key source reading the JSON Web Key Set file at path.
+func NewJWKSFile(path string) (*JWKSFile, error) {
+	f := &JWKSFile{
+		path: path,
+		now:  time.Now,
+	}
+
+	_, err := f.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// SigningKey returns the first key of the file that can sign tokens.
+func (f *JWKSFile) SigningKey() (Key, error) {
+	keys, err := f.load()
+	if err != nil {
+		return Key{}, err
+	}
+
+	for _, k := range keys {
+		if k.CanSign() {
+			return k, nil
+		}
+	}
+	return Key{}, ErrNoSigningKey
+}
+
+// Keys returns the keys of the file.
+func (f *JWKSFile) Keys() ([]Key, error) {
+	return f.load()
+}
+
+// load returns the keys of the file, reading it again if it was modified since last read.
+func (f *JWKSFile) load() ([]Key, error) {
+	f.mu.RLock()
+	keys, modTime := f.keys, f.modTime
+	f.mu.RUnlock()
+
+	now := f.now()
+	if (keys != nil) && (now.Sub(time.Unix(0, f.checkedAt.Load())) < jwksCheckInterval) {
+		return keys, nil
+	}
+	f.checkedAt.Store(now.UnixNano())
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read JWKS file: %w", err)
+	}
+
+	if (keys != nil) && info.ModTime().Equal(modTime) {
+		return keys, nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// the file may have been read again while waiting for the lock
+	if (f.keys != nil) && info.ModTime().Equal(f.modTime) {
+		return f.keys, nil
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read JWKS file: %w", err)
+	}
+
+	keys, err = ParseJWKS(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse JWKS file: %w", err)
+	}
+
+	f.keys, f.modTime = keys, info.ModTime()
+	return keys, nil
+}
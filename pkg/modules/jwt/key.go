@@ -0,0 +1,149 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"fmt"
+	"sync"
+)
+
+// Key is a key tokens are signed or verified with.
+type Key struct {
+	// ID identifies the key in the "kid" header of the tokens it signs
+	ID string
+
+	// Algorithm is the only algorithm the key signs and verifies tokens with
+	Algorithm Algorithm
+
+	// Secret is the secret of HS256 keys
+	Secret []byte
+
+	// PrivateKey is the private key of asymmetric keys that sign tokens, if any:
+	// an *rsa.PrivateKey, an *ecdsa.PrivateKey or an ed25519.PrivateKey
+	PrivateKey crypto.Signer
+
+	// PublicKey is the public key of asymmetric keys:
+	// an *rsa.PublicKey, an *ecdsa.PublicKey or an ed25519.PublicKey
+	PublicKey crypto.PublicKey
+}
+
+// NewKey returns the key identified by id that signs and verifies tokens with the algorithm.
+//
+// The key material depends on the algorithm: a []byte secret for HS256, an *rsa.PrivateKey or
+// *rsa.PublicKey for RS256, a P-256 *ecdsa.PrivateKey or *ecdsa.PublicKey for ES256, and an
+// ed25519.PrivateKey or ed25519.PublicKey for EdDSA. Keys made from public keys only verify tokens.
+func NewKey(id string, alg Algorithm, material any) (Key, error) {
+	k := Key{
+		ID:        id,
+		Algorithm: alg,
+	}
+
+	switch m := material.(type) {
+	case []byte:
+		if alg == HS256 {
+			k.Secret = m
+		}
+	case *rsa.PrivateKey:
+		if alg == RS256 {
+			k.PrivateKey, k.PublicKey = m, &m.PublicKey
+		}
+	case *rsa.PublicKey:
+		if alg == RS256 {
+			k.PublicKey = m
+		}
+	case *ecdsa.PrivateKey:
+		if (alg == ES256) && (m.Curve == elliptic.P256()) {
+			k.PrivateKey, k.PublicKey = m, &m.PublicKey
+		}
+	case *ecdsa.PublicKey:
+		if (alg == ES256) && (m.Curve == elliptic.P256()) {
+			k.PublicKey = m
+		}
+	case ed25519.PrivateKey:
+		if (alg == EdDSA) && (len(m) == ed25519.PrivateKeySize) {
+			k.PrivateKey, k.PublicKey = m, m.Public()
+		}
+	case ed25519.PublicKey:
+		if (alg == EdDSA) && (len(m) == ed25519.PublicKeySize) {
+			k.PublicKey = m
+		}
+	}
+
+	if (len(k.Secret) == 0) && (k.PublicKey == nil) {
+		return Key{}, fmt.Errorf("%w: %T is not a %s key", ErrInvalidKey, material, alg)
+	}
+
+	return k, nil
+}
+
+// CanSign reports if the key can sign tokens.
+func (k Key) CanSign() bool {
+	return (len(k.Secret) > 0) || (k.PrivateKey != nil)
+}
+
+// KeySource is the source of the keys tokens are signed and verified with.
+type KeySource interface {
+	// SigningKey returns the key new tokens are signed with.
+	SigningKey() (Key, error)
+
+	// Keys returns the keys tokens are verified with, including the
+	// keys that were rotated out but still verify unexpired tokens.
+	Keys() ([]Key, error)
+}
+
+// KeySet is an in-memory [KeySource]. Its first key signs tokens until another key is rotated in.
+type KeySet struct {
+	mu   sync.RWMutex
+	keys []Key
+}
+
+// NewKeySet returns a key set holding the keys.
+func NewKeySet(keys ...Key) *KeySet {
+	return &KeySet{
+		keys: keys,
+	}
+}
+
+// SigningKey returns the first key of the set.
+func (s *KeySet) SigningKey() (Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if (len(s.keys) == 0) || (!s.keys[0].CanSign()) {
+		return Key{}, ErrNoSigningKey
+	}
+	return s.keys[0], nil
+}
+
+// Keys returns the keys of the set.
+func (s *KeySet) Keys() ([]Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]Key(nil), s.keys...), nil
+}
+
+// Rotate makes the key the signing key of the set. Previous keys keep verifying tokens until removed.
+func (s *KeySet) Rotate(k Key) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys = append([]Key{k}, s.keys...)
+}
+
+// Remove removes the key identified by id from the set.
+func (s *KeySet) Remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := s.keys[:0:0]
+	for _, k := range s.keys {
+		if k.ID != id {
+			keys = append(keys, k)
+		}
+	}
+	s.keys = keys
+}
@@ -0,0 +1,34 @@
+package jwt
+
+import (
+	"github.com/huboh/vara"
+	"github.com/huboh/vara/pkg/modules/config"
+)
+
+// Module provides the service signing and verifying tokens, configured from the config
+// module. Importers can apply [NewGuard] to their routes as a guard constructor.
+type Module struct {
+	// Keys is the source of the keys tokens are signed and verified with,
+	// instead of the keys configured by [NewConfig]
+	Keys KeySource
+}
+
+func (m *Module) Config() *vara.ModuleConfig {
+	return &vara.ModuleConfig{
+		Imports:            []vara.Module{&config.Module{}},
+		ExportConstructors: []vara.ProviderConstructor{NewService},
+		ProviderConstructors: []vara.ProviderConstructor{
+			NewConfig,
+			m.newKeySource,
+			NewService,
+		},
+	}
+}
+
+// newKeySource returns the module's key source, or the key source configured by [NewConfig] if it has none.
+func (m *Module) newKeySource(c Config) (KeySource, error) {
+	if m.Keys != nil {
+		return m.Keys, nil
+	}
+	return NewKeySource(c)
+}
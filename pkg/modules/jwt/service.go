@@ -0,0 +1,230 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidKey indicates a key is not usable with its algorithm
+	ErrInvalidKey = errors.New("invalid key")
+
+	// ErrNoSigningKey indicates the key source has no key that can sign tokens
+	ErrNoSigningKey = errors.New("no signing key")
+
+	// ErrUnsupportedAlgorithm indicates a token is signed with an algorithm that is not supported
+	ErrUnsupportedAlgorithm = errors.New("unsupported algorithm")
+
+	// ErrMalformedToken indicates a token is not a compact JWS
+	ErrMalformedToken = errors.New("malformed token")
+
+	// ErrKeyNotFound indicates no key of the key source verifies a token's algorithm and key ID
+	ErrKeyNotFound = errors.New("key not found")
+
+	// ErrInvalidSignature indicates a token's signature is invalid
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	// ErrTokenExpired indicates a token has expired
+	ErrTokenExpired = errors.New("token has expired")
+
+	// ErrMissingExpiry indicates a token has no "exp" claim
+	ErrMissingExpiry = errors.New("token has no expiry")
+
+	// ErrTokenNotValidYet indicates a token is used before its "nbf" claim
+	ErrTokenNotValidYet = errors.New("token is not valid yet")
+
+	// ErrInvalidIssuer indicates a token was not issued by the configured issuer
+	ErrInvalidIssuer = errors.New("invalid issuer")
+
+	// ErrInvalidAudience indicates a token is not intended for the configured audience
+	ErrInvalidAudience = errors.New("invalid audience")
+)
+
+// header is the JOSE header of a token.
+type header struct {
+	Alg Algorithm `json:"alg"`
+	Typ string    `json:"typ,omitempty"`
+	Kid string    `json:"kid,omitempty"`
+}
+
+type Service struct {
+	keys   KeySource
+	config Config
+	now    func() time.Time
+}
+
+func NewService(c Config, k KeySource) *Service {
+	return &Service{
+		keys:   k,
+		config: c,
+		now:    time.Now,
+	}
+}
+
+// Sign returns a token holding the claims, signed with the signing key of the key source.
+//
+// The "iat" claim, the "exp" claim if the config sets a TTL, and the "iss" claim if the config
+// sets an issuer, are added unless already set. Time claims may be set as [time.Time] values.
+func (s *Service) Sign(claims Claims) (string, error) {
+	k, err := s.keys.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	now := s.now()
+	claims = claims.clone()
+	setDefault(claims, ClaimIssuedAt, now.Unix())
+	if s.config.TTL > 0 {
+		setDefault(claims, ClaimExpiresAt, now.Add(s.config.TTL).Unix())
+	}
+	if s.config.Issuer != "" {
+		setDefault(claims, ClaimIssuer, s.config.Issuer)
+	}
+	for _, name := range []string{ClaimIssuedAt, ClaimExpiresAt, ClaimNotBefore} {
+		if t, ok := claims[name].(time.Time); ok {
+			claims[name] = t.Unix()
+		}
+	}
+
+	h, err := json.Marshal(header{Alg: k.Algorithm, Typ: "JWT", Kid: k.ID})
+	if err != nil {
+		return "", err
+	}
+
+	c, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("could not encode claims: %w", err)
+	}
+
+	input := encodeSegment(h) + "." + encodeSegment(c)
+	sig, err := k.Algorithm.sign(k, []byte(input))
+	if err != nil {
+		return "", err
+	}
+
+	return input + "." + encodeSegment(sig), nil
+}
+
+// Verify returns the claims of the token once its signature is verified with a key of the key
+// source and its "exp", "nbf", "iss" and "aud" claims are validated against the config.
+// Tokens without an "exp" claim are rejected unless the config allows them.
+func (s *Service) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var h header
+	err := decodeJSONSegment(parts[0], &h)
+	if err != nil {
+		return nil, fmt.Errorf("%w: header: %w", ErrMalformedToken, err)
+	}
+
+	sig, err := decodeSegment(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %w", ErrMalformedToken, err)
+	}
+
+	err = s.verifySignature(h, []byte(parts[0]+"."+parts[1]), sig)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	err = decodeJSONSegment(parts[1], &claims)
+	if err != nil {
+		return nil, fmt.Errorf("%w: claims: %w", ErrMalformedToken, err)
+	}
+
+	err = s.validate(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// verifySignature verifies the signature with the keys matching the header's algorithm and key ID.
+func (s *Service) verifySignature(h header, input, sig []byte) error {
+	switch h.Alg {
+	case HS256, RS256, ES256, EdDSA:
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, h.Alg)
+	}
+
+	keys, err := s.keys.Keys()
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, k := range keys {
+		// keys only verify tokens signed with their own algorithm,
+		// so a public key can't be used as an HMAC secret
+		if (k.Algorithm != h.Alg) || ((h.Kid != "") && (k.ID != h.Kid)) {
+			continue
+		}
+		if h.Alg.verify(k, input, sig) {
+			return nil
+		}
+		found = true
+	}
+
+	if !found {
+		return fmt.Errorf("%w: %s (%s)", ErrKeyNotFound, h.Alg, h.Kid)
+	}
+	return ErrInvalidSignature
+}
+
+// validate validates the time claims and the issuer and audience against the config.
+func (s *Service) validate(c Claims) error {
+	now := s.now()
+
+	exp, ok := c.ExpiresAt()
+	switch {
+	case !ok && !s.config.AllowMissingExpiry:
+		return ErrMissingExpiry
+	case ok && !now.Before(exp.Add(s.config.Leeway)):
+		return ErrTokenExpired
+	}
+
+	if nbf, ok := c.NotBefore(); ok && now.Add(s.config.Leeway).Before(nbf) {
+		return ErrTokenNotValidYet
+	}
+
+	if (s.config.Issuer != "") && (c.Issuer() != s.config.Issuer) {
+		return ErrInvalidIssuer
+	}
+
+	if (s.config.Audience != "") && (!c.hasAudience(s.config.Audience)) {
+		return ErrInvalidAudience
+	}
+
+	return nil
+}
+
+func setDefault(c Claims, name string, value any) {
+	if _, ok := c[name]; !ok {
+		c[name] = value
+	}
+}
+
+func encodeSegment(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeJSONSegment(s string, v any) error {
+	b, err := decodeSegment(s)
+	if err != nil {
+		return err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	return d.Decode(v)
+}
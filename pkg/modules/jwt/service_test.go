@@ -0,0 +1,263 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc7515Claims are the claims of the tokens of RFC 7515, appendix A, which expire at 1300819380.
+const rfc7515Claims = "eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ"
+
+func newTestService(t *testing.T, c Config, keys ...Key) *Service {
+	t.Helper()
+
+	s := NewService(c, NewKeySet(keys...))
+	s.now = func() time.Time { return time.Unix(1300819380, 0).Add(-time.Minute) }
+	return s
+}
+
+func parseTestJWKS(t *testing.T, jwks string) []Key {
+	t.Helper()
+
+	keys, err := ParseJWKS([]byte(jwks))
+	if err != nil {
+		t.Fatalf("ParseJWKS() error = %v", err)
+	}
+	return keys
+}
+
+func newTestKey(t *testing.T, alg Algorithm, material any) Key {
+	t.Helper()
+
+	k, err := NewKey("", alg, material)
+	if err != nil {
+		t.Fatalf("NewKey() error = %v", err)
+	}
+	return k
+}
+
+// signTestToken returns a token with the header and claims, signed with the key.
+func signTestToken(t *testing.T, h header, claims Claims, k Key) string {
+	t.Helper()
+
+	hb, _ := json.Marshal(h)
+	cb, _ := json.Marshal(claims)
+	input := encodeSegment(hb) + "." + encodeSegment(cb)
+
+	sig, err := h.Alg.sign(k, []byte(input))
+	if err != nil {
+		t.Fatalf("sign() error = %v", err)
+	}
+	return input + "." + encodeSegment(sig)
+}
+
+func TestVerifyRFCVectors(t *testing.T) {
+	tests := []struct {
+		name  string
+		jwks  string
+		token string
+	}{
+		{
+			name:  "RFC 7515 A.1 HS256",
+			jwks:  `{"keys":[{"kty":"oct","k":"AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr_T-1qS0gZH75aKtMN3Yj0iPS4hcgUuTwjAzZr1Z9CAow"}]}`,
+			token: "eyJ0eXAiOiJKV1QiLA0KICJhbGciOiJIUzI1NiJ9." + rfc7515Claims + ".dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk",
+		},
+		{
+			name: "RFC 7515 A.3 ES256",
+			jwks: `{"keys":[{"kty":"EC","crv":"P-256",` +
+				`"x":"f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU",` +
+				`"y":"x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0"}]}`,
+			token: "eyJhbGciOiJFUzI1NiJ9." + rfc7515Claims +
+				".DtEhU3ljbEg8L38VWAfUAqOyKAM6-Xx-F4GawxaepmXFCgfTjDxw5djxLa8ISlSApmWQxfKTUJqPP3-Kg6NU1Q",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, Config{Issuer: "joe"}, parseTestJWKS(t, tt.jwks)...)
+
+			claims, err := s.Verify(tt.token)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if claims["http://example.com/is_root"] != true {
+				t.Errorf("claims = %v, want the is_root claim", claims)
+			}
+
+			tampered := strings.Replace(tt.token, ".eyJpc3Mi", ".eyJpc3mi", 1)
+			_, err = s.Verify(tampered)
+			if !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Verify(tampered) error = %v, want %v", err, ErrInvalidSignature)
+			}
+		})
+	}
+}
+
+func TestVerifyRFC8037EdDSA(t *testing.T) {
+	// RFC 8037, appendix A.4. The payload is not a claims set, so only the signature is verified.
+	keys := parseTestJWKS(t, `{"keys":[{"kty":"OKP","crv":"Ed25519",`+
+		`"d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A",`+
+		`"x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}]}`)
+	s := newTestService(t, Config{}, keys...)
+
+	input := "eyJhbGciOiJFZERTQSJ9.RXhhbXBsZSBvZiBFZDI1NTE5IHNpZ25pbmc"
+	sig, err := decodeSegment("hgyY0il_MGCjP0JzlnLWG1PPOt7-09PGcvMg3AIbQR6dWbhijcNR4ki4iylGjg5BhVsPt9g7sVvpAr_MuM0KAg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.verifySignature(header{Alg: EdDSA}, []byte(input), sig)
+	if err != nil {
+		t.Errorf("verifySignature() error = %v", err)
+	}
+}
+
+func TestVerifyRejectsUnsupportedAlgorithms(t *testing.T) {
+	s := newTestService(t, Config{AllowMissingExpiry: true}, newTestKey(t, HS256, []byte("secret")))
+
+	for _, alg := range []string{"none", "None", "HS512", ""} {
+		h, _ := json.Marshal(map[string]string{"alg": alg})
+		token := encodeSegment(h) + "." + encodeSegment([]byte(`{"sub":"joe"}`)) + "."
+
+		_, err := s.Verify(token)
+		if !errors.Is(err, ErrUnsupportedAlgorithm) {
+			t.Errorf("Verify(alg %q) error = %v, want %v", alg, err, ErrUnsupportedAlgorithm)
+		}
+	}
+}
+
+func TestVerifyAlgorithmMismatch(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := newTestService(t, Config{}, newTestKey(t, ES256, &priv.PublicKey))
+	claims := Claims{ClaimExpiresAt: time.Unix(1300819380, 0).Unix()}
+
+	// an HMAC token keyed with the public key must not verify against it
+	forged := signTestToken(t, header{Alg: HS256}, claims, newTestKey(t, HS256, pub))
+	_, err = s.Verify(forged)
+	if !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Verify(HS256 with public key) error = %v, want %v", err, ErrKeyNotFound)
+	}
+
+	// a token claiming another key's algorithm must not verify
+	valid := signTestToken(t, header{Alg: ES256}, claims, newTestKey(t, ES256, priv))
+	parts := strings.Split(valid, ".")
+	h, _ := json.Marshal(header{Alg: EdDSA})
+	_, err = s.Verify(encodeSegment(h) + "." + parts[1] + "." + parts[2])
+	if !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Verify(mismatched alg) error = %v, want %v", err, ErrKeyNotFound)
+	}
+
+	_, err = s.Verify(valid)
+	if err != nil {
+		t.Errorf("Verify(valid) error = %v", err)
+	}
+}
+
+func TestVerifyTimeClaims(t *testing.T) {
+	var (
+		k   = newTestKey(t, HS256, []byte("secret"))
+		now = time.Unix(1300819380, 0).Add(-time.Minute)
+	)
+
+	tests := []struct {
+		name    string
+		config  Config
+		claims  Claims
+		wantErr error
+	}{
+		{"valid", Config{}, Claims{ClaimExpiresAt: now.Add(time.Second).Unix()}, nil},
+		{"expired", Config{}, Claims{ClaimExpiresAt: now.Unix()}, ErrTokenExpired},
+		{"expired within leeway", Config{Leeway: time.Minute}, Claims{ClaimExpiresAt: now.Add(-time.Second).Unix()}, nil},
+		{"missing expiry", Config{}, Claims{ClaimSubject: "joe"}, ErrMissingExpiry},
+		{"missing expiry allowed", Config{AllowMissingExpiry: true}, Claims{ClaimSubject: "joe"}, nil},
+		{"not valid yet", Config{AllowMissingExpiry: true}, Claims{ClaimNotBefore: now.Add(time.Second).Unix()}, ErrTokenNotValidYet},
+		{"not valid yet within leeway", Config{AllowMissingExpiry: true, Leeway: time.Minute}, Claims{ClaimNotBefore: now.Add(time.Second).Unix()}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, tt.config, k)
+
+			_, err := s.Verify(signTestToken(t, header{Alg: HS256}, tt.claims, k))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSignSetsExpiry(t *testing.T) {
+	k := newTestKey(t, HS256, []byte("secret"))
+	s := newTestService(t, Config{TTL: time.Hour}, k)
+
+	token, err := s.Sign(Claims{ClaimSubject: "joe"})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	claims, err := s.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if exp, _ := claims.ExpiresAt(); !exp.Equal(s.now().Add(time.Hour)) {
+		t.Errorf("exp = %v, want %v", exp, s.now().Add(time.Hour))
+	}
+}
+
+func TestJWKSFileChecksModificationsOncePerSecond(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+
+	write := func(kid string, modTime time.Time) {
+		err := os.WriteFile(path, []byte(`{"keys":[{"kty":"oct","kid":"`+kid+`","k":"c2VjcmV0"}]}`), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.Chtimes(path, modTime, modTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keyID := func(f *JWKSFile) string {
+		keys, err := f.Keys()
+		if err != nil {
+			t.Fatalf("Keys() error = %v", err)
+		}
+		return keys[0].ID
+	}
+
+	write("a", time.Now().Add(-time.Hour))
+	f, err := NewJWKSFile(path)
+	if err != nil {
+		t.Fatalf("NewJWKSFile() error = %v", err)
+	}
+	now := time.Now()
+	f.now = func() time.Time { return now }
+
+	write("b", now)
+	if got := keyID(f); got != "a" {
+		t.Errorf("key within a second of the last check = %q, want %q", got, "a")
+	}
+
+	now = now.Add(jwksCheckInterval)
+	if got := keyID(f); got != "b" {
+		t.Errorf("key once the file is checked again = %q, want %q", got, "b")
+	}
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/huboh/vara"
+)
+
+// AccessLog returns a middleware logging every request handled by the wrapped handler,
+// along with the response status and duration.
+func AccessLog(l *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			serveLogged(l, next, w, r)
+		})
+	}
+}
+
+// accessLogInterceptor logs the requests handled by every route of the application.
+type accessLogInterceptor struct {
+	logger *slog.Logger
+}
+
+func newAccessLogInterceptor(in loggerInput) *accessLogInterceptor {
+	return &accessLogInterceptor{
+		logger: in.Logger,
+	}
+}
+
+func (i *accessLogInterceptor) Intercept(route vara.RouteInfo, next http.Handler) http.Handler {
+	l := i.logger.With("module", route.Module, "route", route.Path)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveLogged(l, next, w, r)
+	})
+}
+
+// serveLogged calls next and logs the request once it returns.
+func serveLogged(l *slog.Logger, next http.Handler, w http.ResponseWriter, r *http.Request) {
+	var (
+		rec   = vara.NewStatusRecorder(w)
+		start = time.Now()
+	)
+
+	next.ServeHTTP(rec, r)
+
+	level := slog.LevelInfo
+	if rec.Status() >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+
+	l.LogAttrs(r.Context(), level, "request",
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.Int("status", rec.Status()),
+		slog.Int("bytes", rec.Bytes()),
+		slog.Duration("duration", time.Since(start)),
+		slog.String("remote_addr", r.RemoteAddr),
+	)
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/huboh/vara/pkg/modules/config"
+)
+
+// Format is the format log records are written in.
+type Format string
+
+// recognized log Format
+const (
+	FormatJSON = Format("json")
+	FormatText = Format("text")
+)
+
+// env keys the Config is read from
+const (
+	EnvLevel     = "LOG_LEVEL"
+	EnvFormat    = "LOG_FORMAT"
+	EnvAddSource = "LOG_ADD_SOURCE"
+)
+
+var (
+	// ErrInvalidFormat indicates the log format is not recognized
+	ErrInvalidFormat = errors.New("invalid log format")
+)
+
+type Config struct {
+	// Level is the minimum level of the records that are logged
+	Level slog.Level
+
+	// Format is the format records are written in, either "json" or "text"
+	Format Format
+
+	// AddSource specifies if the source code position of the log statement is added to records
+	AddSource bool
+
+	// Output is where records are written to
+	Output io.Writer
+}
+
+// NewConfig returns the logger config read from the LOG_LEVEL, LOG_FORMAT and
+// LOG_ADD_SOURCE env keys. Records are logged at the info level and above, in
+// JSON, to the standard error by default.
+func NewConfig(c *config.Service) (Config, error) {
+	cfg := Config{
+		Level:  slog.LevelInfo,
+		Format: FormatJSON,
+		Output: os.Stderr,
+	}
+
+	if level, ok := c.Lookup(EnvLevel); ok {
+		err := cfg.Level.UnmarshalText([]byte(level))
+		if err != nil {
+			return Config{}, fmt.Errorf("%s: %w", EnvLevel, err)
+		}
+	}
+
+	if format, ok := c.Lookup(EnvFormat); ok {
+		cfg.Format = Format(strings.ToLower(format))
+		if (cfg.Format != FormatJSON) && (cfg.Format != FormatText) {
+			return Config{}, fmt.Errorf("%s: %w: %q", EnvFormat, ErrInvalidFormat, format)
+		}
+	}
+
+	if addSource, ok := c.Lookup(EnvAddSource); ok {
+		var err error
+		cfg.AddSource, err = strconv.ParseBool(addSource)
+		if err != nil {
+			return Config{}, fmt.Errorf("%s: %w", EnvAddSource, err)
+		}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,35 @@
+// Package logger provides the application's structured logger, built on log/slog.
+//
+// Every module is provided a child *slog.Logger with a `module` attribute, and
+// Vara's own messages are logged with the logger as well.
+package logger
+
+import (
+	"log/slog"
+
+	"github.com/huboh/vara"
+)
+
+// loggerName is the name the application's logger is provided under in the module's scope,
+// so the module's providers use it rather than the child logger provided to every module.
+const loggerName = "logger.logger"
+
+// loggerInput is used for injecting the application's logger into the module's providers.
+type loggerInput struct {
+	vara.In
+	Logger *slog.Logger `name:"logger.logger"`
+}
+
+// NewLogger returns a logger writing records as configured.
+func NewLogger(c Config) *slog.Logger {
+	opts := &slog.HandlerOptions{
+		Level:     c.Level,
+		AddSource: c.AddSource,
+	}
+
+	if c.Format == FormatText {
+		return slog.New(slog.NewTextHandler(c.Output, opts))
+	}
+
+	return slog.New(slog.NewJSONHandler(c.Output, opts))
+}
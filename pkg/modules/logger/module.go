@@ -0,0 +1,30 @@
+package logger
+
+import (
+	"log/slog"
+
+	"github.com/huboh/vara"
+	"github.com/huboh/vara/pkg/modules/config"
+)
+
+// Module provides the application's logger, configured from the config module.
+type Module struct {
+	// AccessLog specifies if the requests handled by every route are logged
+	AccessLog bool
+}
+
+func (m *Module) Config() *vara.ModuleConfig {
+	providers := []vara.ProviderConstructor{
+		NewConfig,
+		vara.Provide(NewLogger, vara.Name(loggerName)),
+		vara.GlobalLogger(func(in loggerInput) *slog.Logger { return in.Logger }),
+	}
+	if m.AccessLog {
+		providers = append(providers, vara.GlobalInterceptor(newAccessLogInterceptor))
+	}
+
+	return &vara.ModuleConfig{
+		Imports:              []vara.Module{&config.Module{}},
+		ProviderConstructors: providers,
+	}
+}
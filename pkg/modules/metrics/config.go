@@ -0,0 +1,16 @@
+package metrics
+
+type Config struct {
+	// Path is the path of the metrics endpoint
+	Path string
+
+	// Buckets are the upper bounds, in seconds, of the request and listener duration histograms
+	Buckets []float64
+}
+
+func NewConfig() Config {
+	return Config{
+		Path:    "/metrics",
+		Buckets: DefaultBuckets,
+	}
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/huboh/vara"
+)
+
+// contentType is the content type of the Prometheus text exposition format.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type controller struct {
+	config  Config
+	metrics *Service
+}
+
+func newController(c Config, s *Service) *controller {
+	return &controller{
+		config:  c,
+		metrics: s,
+	}
+}
+
+func (c *controller) Config() *vara.ControllerConfig {
+	return &vara.ControllerConfig{
+		RouteConfigs: []*vara.RouteConfig{
+			{
+				Pattern: c.config.Path,
+				Method:  http.MethodGet,
+				Handler: http.HandlerFunc(c.handleMetrics),
+			},
+		},
+	}
+}
+
+func (c *controller) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	c.metrics.Write(w)
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/huboh/vara"
+)
+
+// interceptor records the requests handled by every route of the application.
+type interceptor struct {
+	requests        *Counter
+	inFlight        *Gauge
+	duration        *Histogram
+	guardRejections *Counter
+}
+
+func newInterceptor(c Config, s *Service) (*interceptor, error) {
+	var (
+		err error
+		i   = &interceptor{}
+	)
+
+	i.requests, err = s.NewCounter("http_requests_total", "Total number of HTTP requests handled.", "method", "route", "status")
+	if err != nil {
+		return nil, err
+	}
+
+	i.inFlight, err = s.NewGauge("http_requests_in_flight", "Number of HTTP requests currently being handled.", "method", "route")
+	if err != nil {
+		return nil, err
+	}
+
+	i.duration, err = s.NewHistogram("http_request_duration_seconds", "Duration of HTTP requests in seconds.", c.Buckets, "method", "route")
+	if err != nil {
+		return nil, err
+	}
+
+	i.guardRejections, err = s.NewCounter("http_guard_rejections_total", "Total number of HTTP requests rejected by guards.", "method", "route", "guard")
+	if err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+func (i *interceptor) Intercept(route vara.RouteInfo, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// routes are labelled by their path template rather
+		// than the request's path to keep the label's cardinality bounded.
+		i.inFlight.Inc(route.Method, route.Path)
+		defer i.inFlight.Dec(route.Method, route.Path)
+
+		var (
+			rec   = vara.NewStatusRecorder(w)
+			start = time.Now()
+		)
+
+		next.ServeHTTP(rec, r)
+
+		i.duration.Observe(time.Since(start).Seconds(), route.Method, route.Path)
+		i.requests.Inc(route.Method, route.Path, strconv.Itoa(rec.Status()))
+
+		if guard, ok := vara.RejectingGuard(r); ok {
+			i.guardRejections.Inc(route.Method, route.Path, fmt.Sprintf("%T", guard))
+		}
+	})
+}
@@ -0,0 +1,207 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Type is the type of a metric.
+type Type string
+
+// recognized metric Type
+const (
+	TypeGauge     = Type("gauge")
+	TypeCounter   = Type("counter")
+	TypeHistogram = Type("histogram")
+)
+
+// DefaultBuckets are the default histogram buckets, in seconds, suited to request latencies.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// labelSeparator separates label values in the key of a series.
+const labelSeparator = "\xff"
+
+// family is a metric along with all of its labelled series.
+type family struct {
+	mu         sync.Mutex
+	name       string
+	help       string
+	typ        Type
+	buckets    []float64
+	labelNames []string
+	series     map[string]*series
+}
+
+// series is the value of a metric for a single set of label values.
+type series struct {
+	labelValues []string
+
+	// value is the value of a counter or gauge.
+	value float64
+
+	// sum, count and bucketCounts are the observations of a histogram.
+	sum          float64
+	count        uint64
+	bucketCounts []uint64
+}
+
+func newFamily(name, help string, typ Type, labelNames []string, buckets []float64) *family {
+	return &family{
+		name:       name,
+		help:       help,
+		typ:        typ,
+		buckets:    buckets,
+		labelNames: labelNames,
+		series:     make(map[string]*series),
+	}
+}
+
+// with calls fn with the series for the label values, creating it if needed.
+func (f *family) with(labelValues []string, fn func(*series)) {
+	if len(labelValues) != len(f.labelNames) {
+		panic(fmt.Errorf("metric %s: expected %d label values, got %d", f.name, len(f.labelNames), len(labelValues)))
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := strings.Join(labelValues, labelSeparator)
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{
+			labelValues:  append([]string(nil), labelValues...),
+			bucketCounts: make([]uint64, len(f.buckets)),
+		}
+		f.series[key] = s
+	}
+
+	fn(s)
+}
+
+// write writes the family in the Prometheus text exposition format.
+func (f *family) write(b *strings.Builder) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fmt.Fprintf(b, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	fmt.Fprintf(b, "# TYPE %s %s\n", f.name, f.typ)
+
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := f.series[key]
+		if f.typ != TypeHistogram {
+			fmt.Fprintf(b, "%s%s %s\n", f.name, f.formatLabels(s.labelValues, "", 0), formatFloat(s.value))
+			continue
+		}
+
+		var cumulative uint64
+		for i, bound := range f.buckets {
+			cumulative += s.bucketCounts[i]
+			fmt.Fprintf(b, "%s_bucket%s %d\n", f.name, f.formatLabels(s.labelValues, "le", bound), cumulative)
+		}
+		fmt.Fprintf(b, "%s_bucket%s %d\n", f.name, f.formatLabels(s.labelValues, "le", math.Inf(1)), s.count)
+		fmt.Fprintf(b, "%s_sum%s %s\n", f.name, f.formatLabels(s.labelValues, "", 0), formatFloat(s.sum))
+		fmt.Fprintf(b, "%s_count%s %d\n", f.name, f.formatLabels(s.labelValues, "", 0), s.count)
+	}
+}
+
+// formatLabels formats the label pairs of a series, with an optional extra label, e.g. `{method="GET",le="0.5"}`.
+func (f *family) formatLabels(values []string, extraName string, extraValue float64) string {
+	var pairs []string
+	for i, name := range f.labelNames {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", name, escapeLabelValue(values[i])))
+	}
+	if extraName != "" {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", extraName, formatFloat(extraValue)))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Counter is a metric whose value only goes up, e.g. the number of requests served.
+type Counter struct {
+	family *family
+}
+
+// Inc increments the counter of the series with the label values by 1.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v to the counter of the series with the label values. v must not be negative.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		panic(fmt.Errorf("metric %s: counter cannot decrease", c.family.name))
+	}
+	c.family.with(labelValues, func(s *series) { s.value += v })
+}
+
+// Gauge is a metric whose value can go up and down, e.g. the number of requests in flight.
+type Gauge struct {
+	family *family
+}
+
+// Set sets the gauge of the series with the label values to v.
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.family.with(labelValues, func(s *series) { s.value = v })
+}
+
+// Add adds v, which may be negative, to the gauge of the series with the label values.
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	g.family.with(labelValues, func(s *series) { s.value += v })
+}
+
+// Inc increments the gauge of the series with the label values by 1.
+func (g *Gauge) Inc(labelValues ...string) { g.Add(1, labelValues...) }
+
+// Dec decrements the gauge of the series with the label values by 1.
+func (g *Gauge) Dec(labelValues ...string) { g.Add(-1, labelValues...) }
+
+// Histogram is a metric that samples observations into buckets, e.g. request latencies.
+type Histogram struct {
+	family *family
+}
+
+// Observe adds an observation to the histogram of the series with the label values.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.family.with(labelValues, func(s *series) {
+		s.sum += v
+		s.count++
+		for i, bound := range h.family.buckets {
+			if v <= bound {
+				s.bucketCounts[i]++
+				break
+			}
+		}
+	})
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(s)
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"cmp"
+
+	"github.com/huboh/vara"
+	"github.com/huboh/vara/pkg/modules/event"
+)
+
+// Module exposes the application's metrics in the Prometheus text exposition format, and
+// records the requests handled by every route and the events handled by the event service.
+// Zero-valued fields use the defaults from [NewConfig].
+type Module struct {
+	// Path is the path of the metrics endpoint
+	Path string
+
+	// Buckets are the upper bounds, in seconds, of the request and listener duration histograms
+	Buckets []float64
+}
+
+func (m *Module) Config() *vara.ModuleConfig {
+	return &vara.ModuleConfig{
+		ExportConstructors: []vara.ProviderConstructor{NewService},
+		ProviderConstructors: []vara.ProviderConstructor{
+			m.newConfig,
+			NewService,
+			vara.GlobalInterceptor(newInterceptor),
+			event.Observe(newObserver),
+		},
+		ControllerConstructors: []vara.ControllerConstructor{newController},
+	}
+}
+
+// newConfig returns the default config overridden by the module's fields.
+func (m *Module) newConfig() Config {
+	c := NewConfig()
+	c.Path = cmp.Or(m.Path, c.Path)
+	if len(m.Buckets) > 0 {
+		c.Buckets = m.Buckets
+	}
+	return c
+}
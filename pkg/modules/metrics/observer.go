@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"context"
+	"time"
+)
+
+// outcome label values of event metrics
+const (
+	outcomeError   = "error"
+	outcomeSuccess = "success"
+)
+
+// observer records the events emitted and handled by the event service.
+type observer struct {
+	emits    *Counter
+	duration *Histogram
+}
+
+func newObserver(c Config, s *Service) (*observer, error) {
+	var (
+		err error
+		o   = &observer{}
+	)
+
+	o.emits, err = s.NewCounter("event_emits_total", "Total number of events emitted.", "event", "outcome")
+	if err != nil {
+		return nil, err
+	}
+
+	o.duration, err = s.NewHistogram("event_listener_duration_seconds", "Duration of event listeners in seconds.", c.Buckets, "event", "outcome")
+	if err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+func (o *observer) ObserveEmit(ctx context.Context, evt string) (context.Context, func(error)) {
+	return ctx, func(err error) {
+		o.emits.Inc(evt, getOutcome(err))
+	}
+}
+
+func (o *observer) ObserveListener(ctx context.Context, evt string) (context.Context, func(error)) {
+	start := time.Now()
+	return ctx, func(err error) {
+		o.duration.Observe(time.Since(start).Seconds(), evt, getOutcome(err))
+	}
+}
+
+func getOutcome(err error) string {
+	if err != nil {
+		return outcomeError
+	}
+	return outcomeSuccess
+}
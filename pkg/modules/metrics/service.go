@@ -0,0 +1,118 @@
+// Package metrics provides metrics recording and a `/metrics` endpoint
+// in the Prometheus text exposition format.
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrInvalidName indicates a metric or label name is not valid
+	ErrInvalidName = errors.New("invalid metric name")
+
+	// ErrDuplicateMetric indicates a metric with the same name was already registered
+	ErrDuplicateMetric = errors.New("metric already registered")
+)
+
+// namePattern matches valid metric and label names.
+var namePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// Service is a registry of metrics that can be exposed to Prometheus.
+type Service struct {
+	mu       sync.RWMutex
+	families map[string]*family
+}
+
+func NewService() *Service {
+	return &Service{
+		families: make(map[string]*family),
+	}
+}
+
+// NewCounter registers a counter with the given name, help text and label names.
+func (s *Service) NewCounter(name, help string, labelNames ...string) (*Counter, error) {
+	f, err := s.register(name, help, TypeCounter, labelNames, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Counter{family: f}, nil
+}
+
+// NewGauge registers a gauge with the given name, help text and label names.
+func (s *Service) NewGauge(name, help string, labelNames ...string) (*Gauge, error) {
+	f, err := s.register(name, help, TypeGauge, labelNames, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Gauge{family: f}, nil
+}
+
+// NewHistogram registers a histogram with the given name, help text, upper bounds of
+// its buckets and label names. [DefaultBuckets] are used if buckets is empty.
+func (s *Service) NewHistogram(name, help string, buckets []float64, labelNames ...string) (*Histogram, error) {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	f, err := s.register(name, help, TypeHistogram, labelNames, buckets)
+	if err != nil {
+		return nil, err
+	}
+	return &Histogram{family: f}, nil
+}
+
+// Write writes every registered metric in the Prometheus text exposition format.
+func (s *Service) Write(w io.Writer) error {
+	s.mu.RLock()
+	names := make([]string, 0, len(s.families))
+	for name := range s.families {
+		names = append(names, name)
+	}
+	s.mu.RUnlock()
+
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		s.mu.RLock()
+		f := s.families[name]
+		s.mu.RUnlock()
+
+		f.write(&b)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (s *Service) register(name, help string, typ Type, labelNames []string, buckets []float64) (*family, error) {
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	for _, label := range labelNames {
+		if (!namePattern.MatchString(label)) || (strings.HasPrefix(label, "__")) || (label == "le") {
+			return nil, fmt.Errorf("%w: label %q of %s", ErrInvalidName, label, name)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.families[name]; exists {
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateMetric, name)
+	}
+
+	f := newFamily(name, help, typ, labelNames, buckets)
+	s.families[name] = f
+
+	return f, nil
+}
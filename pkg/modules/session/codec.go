@@ -0,0 +1,144 @@
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// minKeySize is the minimum size of the keys cookies are signed and encrypted with.
+const minKeySize = 32
+
+// labels of the keys derived from each of the configured keys
+const (
+	labelSign    = "vara.session.sign"
+	labelEncrypt = "vara.session.encrypt"
+)
+
+// codec signs and encrypts the values of session cookies. Values are signed and encrypted with
+// the first key, and verified and decrypted with any of the keys, so keys are rotated by
+// prepending the new key and removing the old one once the cookies it issued have expired.
+type codec struct {
+	signKeys [][]byte
+	aeads    []cipher.AEAD
+}
+
+func newCodec(keys [][]byte) (*codec, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	c := &codec{}
+	for i, key := range keys {
+		if len(key) < minKeySize {
+			return nil, fmt.Errorf("%w: key %d is shorter than %d bytes", ErrInvalidKey, i, minKeySize)
+		}
+
+		block, err := aes.NewCipher(deriveKey(key, labelEncrypt))
+		if err != nil {
+			return nil, err
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+
+		c.aeads = append(c.aeads, aead)
+		c.signKeys = append(c.signKeys, deriveKey(key, labelSign))
+	}
+
+	return c, nil
+}
+
+// sign returns the signed cookie value of a session ID, bound to the cookie's name.
+func (c *codec) sign(name, id string) string {
+	return id + "." + base64.RawURLEncoding.EncodeToString(mac(c.signKeys[0], name, id))
+}
+
+// verify returns the session ID of a signed cookie value, reporting if its signature is valid.
+func (c *codec) verify(name, value string) (string, bool) {
+	id, encoded, ok := strings.Cut(value, ".")
+	if !ok {
+		return "", false
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", false
+	}
+
+	for _, key := range c.signKeys {
+		if hmac.Equal(sig, mac(key, name, id)) {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// encrypt returns the encrypted cookie value of plaintext, bound to the cookie's name.
+func (c *codec) encrypt(name string, plaintext []byte) (string, error) {
+	aead := c.aeads[0]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, []byte(name))), nil
+}
+
+// decrypt returns the plaintext of an encrypted cookie value, reporting if it was authenticated.
+func (c *codec) decrypt(name, value string) ([]byte, bool) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, false
+	}
+
+	for _, aead := range c.aeads {
+		if len(data) < aead.NonceSize() {
+			continue
+		}
+
+		nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+		plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(name))
+		if err == nil {
+			return plaintext, true
+		}
+	}
+	return nil, false
+}
+
+// deriveKey derives a key for a single use from a configured key,
+// so the same key is never used both to sign and to encrypt.
+func deriveKey(key []byte, label string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(label))
+	return h.Sum(nil)
+}
+
+func mac(key []byte, name, id string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(id))
+	return h.Sum(nil)
+}
+
+// idSize is the number of random bytes of session IDs.
+const idSize = 32
+
+// newID returns a new random session ID.
+func newID() (string, error) {
+	b := make([]byte, idSize)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,128 @@
+package session
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+// testKey returns a valid key made of the byte b.
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, minKeySize)
+}
+
+func newTestCodec(t *testing.T, keys ...[]byte) *codec {
+	t.Helper()
+
+	c, err := newCodec(keys)
+	if err != nil {
+		t.Fatalf("newCodec() error = %v", err)
+	}
+	return c
+}
+
+func TestNewCodec(t *testing.T) {
+	_, err := newCodec(nil)
+	if !errors.Is(err, ErrNoKeys) {
+		t.Errorf("newCodec(nil) error = %v, want %v", err, ErrNoKeys)
+	}
+
+	_, err = newCodec([][]byte{testKey(1), testKey(2)[:minKeySize-1]})
+	if !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("newCodec(short key) error = %v, want %v", err, ErrInvalidKey)
+	}
+}
+
+func TestCodecSign(t *testing.T) {
+	c := newTestCodec(t, testKey(1))
+	value := c.sign("session", "id")
+
+	if id, ok := c.verify("session", value); !ok || (id != "id") {
+		t.Errorf("verify() = %q, %v, want %q, true", id, ok, "id")
+	}
+
+	_, sig, _ := bytes.Cut([]byte(value), []byte("."))
+	for name, v := range map[string]string{
+		"other cookie": "",
+		"other id":     "other." + string(sig),
+		"no signature": "id",
+		"not base64":   "id.!!",
+		"other key":    newTestCodec(t, testKey(2)).sign("session", "id"),
+	} {
+		cookie := "session"
+		if name == "other cookie" {
+			cookie, v = "other", value
+		}
+		if _, ok := c.verify(cookie, v); ok {
+			t.Errorf("verify(%s) = true, want false", name)
+		}
+	}
+}
+
+func TestCodecEncrypt(t *testing.T) {
+	c := newTestCodec(t, testKey(1))
+
+	value, err := c.encrypt("session", []byte("plaintext"))
+	if err != nil {
+		t.Fatalf("encrypt() error = %v", err)
+	}
+
+	if got, ok := c.decrypt("session", value); !ok || (string(got) != "plaintext") {
+		t.Errorf("decrypt() = %q, %v, want %q, true", got, ok, "plaintext")
+	}
+
+	if _, ok := c.decrypt("other", value); ok {
+		t.Error("decrypt() of another cookie's value = true, want false")
+	}
+
+	data, _ := base64.RawURLEncoding.DecodeString(value)
+	data[len(data)-1] ^= 1
+	if _, ok := c.decrypt("session", base64.RawURLEncoding.EncodeToString(data)); ok {
+		t.Error("decrypt() of a tampered value = true, want false")
+	}
+
+	if _, ok := c.decrypt("session", "AAAA"); ok {
+		t.Error("decrypt() of a short value = true, want false")
+	}
+}
+
+func TestCodecKeyRotation(t *testing.T) {
+	var (
+		old     = newTestCodec(t, testKey(1))
+		rotated = newTestCodec(t, testKey(2), testKey(1))
+		dropped = newTestCodec(t, testKey(2))
+	)
+
+	signed := old.sign("session", "id")
+	encrypted, err := old.encrypt("session", []byte("plaintext"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// values issued with the previous key are accepted until it is removed
+	if _, ok := rotated.verify("session", signed); !ok {
+		t.Error("rotated verify() of a value signed with the previous key = false, want true")
+	}
+	if _, ok := rotated.decrypt("session", encrypted); !ok {
+		t.Error("rotated decrypt() of a value encrypted with the previous key = false, want true")
+	}
+	if _, ok := dropped.verify("session", signed); ok {
+		t.Error("verify() of a value signed with a removed key = true, want false")
+	}
+	if _, ok := dropped.decrypt("session", encrypted); ok {
+		t.Error("decrypt() of a value encrypted with a removed key = true, want false")
+	}
+
+	// values are issued with the new key
+	if _, ok := dropped.verify("session", rotated.sign("session", "id")); !ok {
+		t.Error("rotated sign() did not use the new key")
+	}
+	encrypted, err = rotated.encrypt("session", []byte("plaintext"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := dropped.decrypt("session", encrypted); !ok {
+		t.Error("rotated encrypt() did not use the new key")
+	}
+}
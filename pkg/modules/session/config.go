@@ -0,0 +1,47 @@
+package session
+
+import (
+	"net/http"
+	"time"
+)
+
+type Config struct {
+	// CookieName is the name of the session cookie
+	CookieName string
+
+	// CookiePath is the path the session cookie is sent for
+	CookiePath string
+
+	// CookieDomain is the domain the session cookie is sent to, or empty for the host that set it
+	CookieDomain string
+
+	// Secure specifies if the session cookie is only sent over HTTPS
+	Secure bool
+
+	// SameSite restricts the cross-site requests the session cookie is sent with
+	SameSite http.SameSite
+
+	// IdleTimeout is how long a session expires after once it is no longer used
+	IdleTimeout time.Duration
+
+	// AbsoluteTimeout is how long a session expires after once created, however much it is used
+	AbsoluteTimeout time.Duration
+
+	// Keys are the keys session cookies are signed and encrypted with, of at least 32 bytes.
+	// Cookies are issued with the first key and accepted with any of them, so keys are rotated
+	// by prepending the new key.
+	Keys [][]byte
+}
+
+// NewConfig returns the default config, of secure cookies named "session" expiring
+// after 30 minutes of inactivity, or 12 hours. It has no keys.
+func NewConfig() Config {
+	return Config{
+		CookieName:      "session",
+		CookiePath:      "/",
+		Secure:          true,
+		SameSite:        http.SameSiteLaxMode,
+		IdleTimeout:     30 * time.Minute,
+		AbsoluteTimeout: 12 * time.Hour,
+	}
+}
@@ -0,0 +1,109 @@
+package session
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+
+	"github.com/huboh/vara"
+)
+
+// names the CSRF token of a request is read from
+const (
+	CSRFHeader = "X-CSRF-Token"
+	CSRFField  = "csrf_token"
+)
+
+// csrfKey is the session key the CSRF token of a session is stored under.
+const csrfKey = "_csrf"
+
+var (
+	// ErrInvalidCSRFToken indicates a request carries no CSRF token, or not the token of its session
+	ErrInvalidCSRFToken = errors.New("invalid CSRF token")
+)
+
+// CSRFToken returns the CSRF token of the request's session, creating it if the session has
+// none. It is rendered in forms as the csrf_token field, or sent by scripts in the
+// X-CSRF-Token header, for the requests to be allowed by a [CSRFGuard].
+func (s *Service) CSRFToken(r *http.Request) (string, error) {
+	sess, err := s.get(r)
+	if err != nil {
+		return "", err
+	}
+
+	token, ok := Get[string](sess, csrfKey)
+	if ok {
+		return token, nil
+	}
+
+	token, err = newCSRFToken()
+	if err != nil {
+		return "", err
+	}
+	return token, sess.Set(csrfKey, token)
+}
+
+// newCSRFToken returns a new random CSRF token.
+func newCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CSRFGuard protects routes from cross-site request forgery with the synchronizer token pattern.
+// Requests with unsafe methods are only allowed if they carry the CSRF token of their session,
+// as returned by [Service.CSRFToken], in the X-CSRF-Token header or the csrf_token form field.
+// Other requests are rejected with a 403 Forbidden.
+type CSRFGuard struct {
+	service *Service
+}
+
+// NewCSRFGuard returns a guard checking CSRF tokens against the sessions of the service.
+// It is a [vara.GuardConstructor], and can be applied to every route with [vara.GlobalGuard].
+func NewCSRFGuard(s *Service) *CSRFGuard {
+	return &CSRFGuard{
+		service: s,
+	}
+}
+
+func (g *CSRFGuard) Allow(gCtx vara.GuardContext) (bool, error) {
+	r := gCtx.Http.R
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true, nil
+	}
+
+	sess, err := g.service.get(r)
+	if err != nil {
+		return false, err
+	}
+
+	err = verifyCSRFToken(sess, r)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// verifyCSRFToken checks that the request carries the CSRF token of its session.
+func verifyCSRFToken(sess *Session, r *http.Request) error {
+	expected, ok := Get[string](sess, csrfKey)
+	if !ok {
+		return vara.ErrForbidden.Wrap(ErrInvalidCSRFToken)
+	}
+
+	token := r.Header.Get(CSRFHeader)
+	if token == "" {
+		token = r.PostFormValue(CSRFField)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+		return vara.ErrForbidden.Wrap(ErrInvalidCSRFToken)
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package session
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fileExt is the extension of the files sessions are saved to.
+const fileExt = ".json"
+
+// tmpPrefix is the prefix of the temporary files sessions are written to before being saved.
+const tmpPrefix = ".tmp-"
+
+// fileRecord is a session as saved to a file.
+type fileRecord struct {
+	Record
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// FileStore is a [SessionStore] saving each session to a file of a directory, so sessions survive
+// restarts of a single instance of an application. The files of expired sessions are deleted in
+// the background, and files the store did not create are left untouched.
+type FileStore struct {
+	dir       string
+	now       func() time.Time
+	mu        sync.Mutex
+	lastSweep time.Time
+	sweeping  bool
+}
+
+// NewFileStore returns the store saving sessions to the directory at dir, creating it if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, fmt.Errorf("could not create sessions directory: %w", err)
+	}
+
+	return &FileStore{
+		dir: dir,
+		now: time.Now,
+	}, nil
+}
+
+func (s *FileStore) Load(ctx context.Context, id string) (Record, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return Record{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Record{}, ErrNotFound
+	}
+	if err != nil {
+		return Record{}, fmt.Errorf("could not read session file: %w", err)
+	}
+
+	var rec fileRecord
+	err = json.Unmarshal(data, &rec)
+	if err != nil {
+		return Record{}, fmt.Errorf("could not parse session file: %w", err)
+	}
+
+	if !s.now().Before(rec.ExpiresAt) {
+		_ = s.Delete(ctx, id)
+		return Record{}, ErrNotFound
+	}
+
+	return rec.Record, nil
+}
+
+func (s *FileStore) Save(_ context.Context, id string, rec Record, ttl time.Duration) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	s.startSweep(now)
+
+	expiresAt := now.Add(ttl)
+	data, err := json.Marshal(fileRecord{Record: rec, ExpiresAt: expiresAt})
+	if err != nil {
+		return err
+	}
+
+	// the session is written to a temporary file first so it is never read partially written
+	tmp, err := os.CreateTemp(s.dir, tmpPrefix+"*")
+	if err != nil {
+		return fmt.Errorf("could not write session file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if cErr := tmp.Close(); err == nil {
+		err = cErr
+	}
+	if err == nil {
+		// the file is modified at the time the session expires, so it is swept without being read
+		err = os.Chtimes(tmp.Name(), expiresAt, expiresAt)
+	}
+	if err != nil {
+		return fmt.Errorf("could not write session file: %w", err)
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return fmt.Errorf("could not write session file: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) Delete(_ context.Context, id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if (err != nil) && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("could not delete session file: %w", err)
+	}
+	return nil
+}
+
+// path returns the path of the file of the session saved under the ID. IDs are only made of
+// URL-safe base64 characters, so they can't point outside the directory.
+func (s *FileStore) path(id string) (string, error) {
+	if (id == "") || strings.IndexFunc(id, func(r rune) bool { return !isIDChar(r) }) >= 0 {
+		return "", ErrInvalidID
+	}
+	return filepath.Join(s.dir, id+fileExt), nil
+}
+
+// startSweep sweeps the directory in the background, at most once per sweep interval.
+func (s *FileStore) startSweep(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sweeping || (now.Sub(s.lastSweep) < sweepInterval) {
+		return
+	}
+	s.lastSweep, s.sweeping = now, true
+
+	go func() {
+		s.sweep(now)
+
+		s.mu.Lock()
+		s.sweeping = false
+		s.mu.Unlock()
+	}()
+}
+
+// sweep deletes the files of the sessions that have expired, and the temporary files of saves
+// that did not complete. Other files of the directory are left untouched.
+func (s *FileStore) sweep(now time.Time) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		name := entry.Name()
+		switch {
+		case isSessionFile(name):
+			if now.Before(info.ModTime()) {
+				continue
+			}
+		case strings.HasPrefix(name, tmpPrefix):
+			// saves in progress write their temporary files right away
+			if now.Sub(info.ModTime()) < sweepInterval {
+				continue
+			}
+		default:
+			continue
+		}
+
+		_ = os.Remove(filepath.Join(s.dir, name))
+	}
+}
+
+// isSessionFile reports if a file is named like the files sessions are saved to.
+func isSessionFile(name string) bool {
+	id, ok := strings.CutSuffix(name, fileExt)
+	return ok && (len(id) == base64.RawURLEncoding.EncodedLen(idSize)) &&
+		(strings.IndexFunc(id, func(r rune) bool { return !isIDChar(r) }) < 0)
+}
+
+func isIDChar(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || (r == '-') || (r == '_')
+}
@@ -0,0 +1,138 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestFileStore(t *testing.T, clock *testClock) *FileStore {
+	t.Helper()
+
+	s, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	s.now = clock.Now
+	return s
+}
+
+func TestFileStore(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		clock = newTestClock()
+		s     = newTestFileStore(t, clock)
+		rec   = Record{Values: map[string]json.RawMessage{"user": json.RawMessage(`"alice"`)}}
+	)
+
+	id, err := newID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Save(ctx, id, rec, time.Minute)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := s.Load(ctx, id)
+	if (err != nil) || (string(got.Values["user"]) != `"alice"`) {
+		t.Errorf("Load() = %+v, %v, want the saved record", got, err)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	_, err = s.Load(ctx, id)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load(expired) error = %v, want %v", err, ErrNotFound)
+	}
+	if _, err := os.Stat(filepath.Join(s.dir, id+fileExt)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the file of an expired session was not deleted: %v", err)
+	}
+
+	for _, id := range []string{"", "../secret", "a/b", "a.b"} {
+		if err := s.Save(ctx, id, rec, time.Minute); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("Save(%q) error = %v, want %v", id, err, ErrInvalidID)
+		}
+	}
+}
+
+func TestFileStoreSweep(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		clock = newTestClock()
+		s     = newTestFileStore(t, clock)
+	)
+
+	// the directory was just swept, so the sessions saved below don't start sweeping it
+	s.lastSweep = clock.now
+
+	newSessionFile := func(ttl time.Duration) string {
+		id, err := newID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = s.Save(ctx, id, Record{}, ttl)
+		if err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		return id + fileExt
+	}
+
+	newFile := func(name string, modTime time.Time) string {
+		path := filepath.Join(s.dir, name)
+		err := os.WriteFile(path, []byte("{"), 0o600)
+		if err == nil {
+			err = os.Chtimes(path, modTime, modTime)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return name
+	}
+
+	var (
+		expired  = newSessionFile(time.Minute)
+		live     = newSessionFile(time.Hour)
+		staleTmp = newFile(tmpPrefix+"stale", clock.now.Add(-sweepInterval))
+		freshTmp = newFile(tmpPrefix+"fresh", clock.now.Add(time.Minute))
+		foreign  = []string{
+			newFile("notes.json", clock.now.Add(-time.Hour)),
+			newFile("short.json", clock.now.Add(-time.Hour)),
+			newFile("readme.txt", clock.now.Add(-time.Hour)),
+		}
+	)
+
+	clock.now = clock.now.Add(time.Minute)
+	s.sweep(clock.now)
+
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(s.dir, name))
+		return err == nil
+	}
+
+	for _, name := range []string{expired, staleTmp} {
+		if exists(name) {
+			t.Errorf("%s was not swept", name)
+		}
+	}
+	for _, name := range append([]string{live, freshTmp}, foreign...) {
+		if !exists(name) {
+			t.Errorf("%s was swept", name)
+		}
+	}
+
+	// saving sweeps the directory in the background once per sweep interval
+	clock.now = clock.now.Add(time.Hour)
+	newSessionFile(time.Hour)
+
+	for deadline := time.Now().Add(5 * time.Second); exists(live); {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s was not swept in the background", live)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
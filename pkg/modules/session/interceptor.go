@@ -0,0 +1,67 @@
+package session
+
+import (
+	"net/http"
+
+	"github.com/huboh/vara"
+)
+
+// interceptor makes the sessions of the requests handled by every route of the application
+// available to the service, and saves them once the route's handler is done with them.
+type interceptor struct {
+	service *Service
+}
+
+func newInterceptor(s *Service) *interceptor {
+	return &interceptor{
+		service: s,
+	}
+}
+
+func (i *interceptor) Intercept(_ vara.RouteInfo, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		st := &state{}
+		stateKey.Set(vara.FromRequest(r), st)
+
+		i.serve(w, r, st, next)
+	})
+}
+
+// serve calls next with a writer saving the session of the state before the response's header is written.
+func (i *interceptor) serve(w http.ResponseWriter, r *http.Request, st *state, next http.Handler) {
+	sw := &sessionWriter{
+		ResponseWriter: w,
+		commit:         func() { i.service.commit(w, r, st) },
+	}
+
+	next.ServeHTTP(sw, r)
+	sw.commit()
+}
+
+// sessionWriter saves the session of a request before the response's header is written,
+// since the session cookie can't be set afterwards.
+type sessionWriter struct {
+	http.ResponseWriter
+	commit func()
+}
+
+func (w *sessionWriter) WriteHeader(status int) {
+	w.commit()
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *sessionWriter) Write(b []byte) (int, error) {
+	w.commit()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *sessionWriter) Flush() {
+	w.commit()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *sessionWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,71 @@
+package session
+
+import (
+	"cmp"
+	"net/http"
+	"time"
+
+	"github.com/huboh/vara"
+)
+
+// Module manages the sessions of the requests handled by every route of the application.
+// Sessions are kept in encrypted cookies, unless the module has a store. Sessions kept in
+// cookies can't be revoked before they expire, since a client may keep sending a previous
+// cookie, so a store is preferred where that matters. Zero-valued fields use the defaults
+// from [NewConfig].
+type Module struct {
+	// Store is the store sessions are kept in, whose cookies only carry their signed ID
+	Store SessionStore
+
+	// Keys are the keys session cookies are signed and encrypted with, newest first
+	Keys [][]byte
+
+	// CookieName is the name of the session cookie
+	CookieName string
+
+	// CookieDomain is the domain the session cookie is sent to
+	CookieDomain string
+
+	// SameSite restricts the cross-site requests the session cookie is sent with
+	SameSite http.SameSite
+
+	// Insecure specifies if the session cookie is also sent over plain HTTP, e.g. in development
+	Insecure bool
+
+	// IdleTimeout is how long a session expires after once it is no longer used
+	IdleTimeout time.Duration
+
+	// AbsoluteTimeout is how long a session expires after once created
+	AbsoluteTimeout time.Duration
+}
+
+func (m *Module) Config() *vara.ModuleConfig {
+	return &vara.ModuleConfig{
+		ExportConstructors: []vara.ProviderConstructor{NewService, NewAccessor},
+		ProviderConstructors: []vara.ProviderConstructor{
+			m.newConfig,
+			m.newStore,
+			NewService,
+			NewAccessor,
+			vara.GlobalInterceptor(newInterceptor),
+		},
+	}
+}
+
+// newConfig returns the default config overridden by the module's fields.
+func (m *Module) newConfig() Config {
+	c := NewConfig()
+	c.Keys = m.Keys
+	c.CookieName = cmp.Or(m.CookieName, c.CookieName)
+	c.CookieDomain = cmp.Or(m.CookieDomain, c.CookieDomain)
+	c.SameSite = cmp.Or(m.SameSite, c.SameSite)
+	c.Secure = !m.Insecure
+	c.IdleTimeout = cmp.Or(m.IdleTimeout, c.IdleTimeout)
+	c.AbsoluteTimeout = cmp.Or(m.AbsoluteTimeout, c.AbsoluteTimeout)
+	return c
+}
+
+// newStore returns the module's store, or nil to keep sessions in encrypted cookies.
+func (m *Module) newStore() SessionStore {
+	return m.Store
+}
@@ -0,0 +1,298 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/huboh/vara"
+)
+
+// maxCookieSize is the size of the largest cookie browsers are required to store, as defined by RFC 6265.
+const maxCookieSize = 4096
+
+var (
+	// ErrNoKeys indicates the config has no keys to sign and encrypt session cookies with
+	ErrNoKeys = errors.New("no session keys")
+
+	// ErrInvalidKey indicates a key is too short to sign and encrypt session cookies with
+	ErrInvalidKey = errors.New("invalid session key")
+
+	// ErrInvalidTimeout indicates the config has neither an idle nor an absolute timeout
+	ErrInvalidTimeout = errors.New("invalid session timeout")
+
+	// ErrNotFound indicates a store has no session under an ID
+	ErrNotFound = errors.New("session not found")
+
+	// ErrInvalidID indicates a session ID is malformed
+	ErrInvalidID = errors.New("invalid session ID")
+
+	// ErrNoSession indicates a request is not handled by a route the session interceptor is applied to
+	ErrNoSession = errors.New("no session for request")
+
+	// ErrCookieTooLarge indicates a session is too large to be saved to a cookie
+	ErrCookieTooLarge = errors.New("session cookie too large")
+)
+
+// Accessor returns the session of a request handled by a route, loading it the first time it is
+// accessed, or creating a new one if the request has no valid session. Changes to the session are
+// saved once the handler starts writing the response, or returns. It is provided to the modules
+// importing the module:
+//
+//	func NewAdminController(sessions session.Accessor) *AdminController {
+//		...
+//	}
+//
+//	func (c *AdminController) dashboard(w http.ResponseWriter, r *http.Request) {
+//		sess, err := c.sessions(r)
+//		if err != nil {
+//			...
+//		}
+//
+//		userID, ok := session.Get[string](sess, "user_id")
+//		...
+//	}
+type Accessor func(r *http.Request) (*Session, error)
+
+// NewAccessor returns the accessor of the sessions of the service.
+func NewAccessor(s *Service) Accessor {
+	return s.get
+}
+
+// stateKey is the key the session state of a request is stored under.
+var stateKey = vara.NewKey[*state]("session.state")
+
+// state is the session of a request, loaded the first time it is accessed.
+type state struct {
+	mu        sync.Mutex
+	session   *Session
+	err       error
+	loaded    bool
+	committed bool
+	hadCookie bool
+}
+
+// cookieRecord is a session as saved to an encrypted cookie.
+type cookieRecord struct {
+	ID string `json:"id"`
+	Record
+}
+
+// Service manages the sessions of requests. Sessions are kept in a [SessionStore], or in
+// encrypted cookies if there is none.
+type Service struct {
+	now    func() time.Time
+	codec  *codec
+	store  SessionStore
+	config Config
+	logger *slog.Logger
+}
+
+// NewService returns a service keeping sessions in the store, or in encrypted cookies if s is nil.
+func NewService(c Config, s SessionStore, l *slog.Logger) (*Service, error) {
+	if (c.IdleTimeout <= 0) && (c.AbsoluteTimeout <= 0) {
+		return nil, ErrInvalidTimeout
+	}
+
+	codec, err := newCodec(c.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		now:    time.Now,
+		codec:  codec,
+		store:  s,
+		config: c,
+		logger: l,
+	}, nil
+}
+
+// get returns the session of the request, as returned by the service's [Accessor].
+func (s *Service) get(r *http.Request) (*Session, error) {
+	st, ok := stateKey.Get(vara.FromRequest(r))
+	if !ok {
+		return nil, ErrNoSession
+	}
+	return s.access(st, r)
+}
+
+// access returns the session of the state, loading it for the request the first time it is accessed.
+func (s *Service) access(st *state, r *http.Request) (*Session, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if !st.loaded {
+		st.session, st.hadCookie, st.err = s.load(r)
+		st.loaded = true
+	}
+	return st.session, st.err
+}
+
+// load returns the session of the request's cookie, or a new session if it has no valid
+// session, and reports if the request had a session cookie.
+func (s *Service) load(r *http.Request) (*Session, bool, error) {
+	now := s.now()
+
+	cookie, err := r.Cookie(s.config.CookieName)
+	if err != nil {
+		sess, err := newSession(s.now)
+		return sess, false, err
+	}
+
+	var loaded *Session
+	if s.store == nil {
+		plaintext, ok := s.codec.decrypt(s.config.CookieName, cookie.Value)
+
+		var rec cookieRecord
+		if ok && (json.Unmarshal(plaintext, &rec) == nil) && (rec.ID != "") {
+			loaded = fromRecord(rec.ID, rec.Record, s.now)
+		}
+	} else if id, ok := s.codec.verify(s.config.CookieName, cookie.Value); ok {
+		rec, err := s.store.Load(r.Context(), id)
+		if (err != nil) && !errors.Is(err, ErrNotFound) {
+			return nil, true, fmt.Errorf("could not load session: %w", err)
+		}
+		if err == nil {
+			loaded = fromRecord(id, rec, s.now)
+		}
+	}
+
+	if (loaded != nil) && !s.expired(loaded, now) {
+		return loaded, true, nil
+	}
+
+	sess, err := newSession(s.now)
+	if (err == nil) && (loaded != nil) {
+		sess.stale = append(sess.stale, loaded.id)
+	}
+	return sess, true, err
+}
+
+// commit saves the session of the request if it was accessed and changed, or must be
+// kept alive, and sets the session cookie. It is called once per request, before the
+// response's header is written.
+func (s *Service) commit(w http.ResponseWriter, r *http.Request, st *state) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if !st.loaded || st.committed || (st.session == nil) {
+		return
+	}
+	st.committed = true
+
+	sess := st.session
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	ctx := r.Context()
+	if s.store != nil {
+		for _, id := range sess.stale {
+			err := s.store.Delete(ctx, id)
+			if err != nil {
+				s.logger.ErrorContext(ctx, "could not delete session", "error", err)
+			}
+		}
+	}
+	sess.stale = nil
+
+	if sess.isNew && !sess.modified {
+		if st.hadCookie {
+			http.SetCookie(w, s.cookie("", -1))
+		}
+		return
+	}
+
+	now := s.now()
+	if !sess.modified && (now.Sub(sess.lastSeen) < s.touchInterval()) {
+		return
+	}
+
+	ttl := s.ttl(sess, now)
+	value, err := s.save(r, sess, sess.record(now), ttl)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "could not save session", "error", err)
+		return
+	}
+
+	sess.lastSeen = now
+	sess.modified = false
+	http.SetCookie(w, s.cookie(value, ttl))
+}
+
+// save saves the session until the ttl elapses, and returns the value of its cookie.
+func (s *Service) save(r *http.Request, sess *Session, rec Record, ttl time.Duration) (string, error) {
+	if s.store != nil {
+		err := s.store.Save(r.Context(), sess.id, rec, ttl)
+		if err != nil {
+			return "", err
+		}
+		return s.codec.sign(s.config.CookieName, sess.id), nil
+	}
+
+	plaintext, err := json.Marshal(cookieRecord{ID: sess.id, Record: rec})
+	if err != nil {
+		return "", err
+	}
+
+	value, err := s.codec.encrypt(s.config.CookieName, plaintext)
+	if err != nil {
+		return "", err
+	}
+
+	if len(s.config.CookieName)+len(value) > maxCookieSize {
+		return "", ErrCookieTooLarge
+	}
+	return value, nil
+}
+
+// expired reports if the session has been idle, or has existed, for longer than allowed.
+func (s *Service) expired(sess *Session, now time.Time) bool {
+	idle, absolute := s.config.IdleTimeout, s.config.AbsoluteTimeout
+	return ((idle > 0) && (now.Sub(sess.lastSeen) >= idle)) ||
+		((absolute > 0) && (now.Sub(sess.createdAt) >= absolute))
+}
+
+// ttl returns the time until the session expires if it is no longer used.
+func (s *Service) ttl(sess *Session, now time.Time) time.Duration {
+	ttl := s.config.IdleTimeout
+	if s.config.AbsoluteTimeout > 0 {
+		remaining := sess.createdAt.Add(s.config.AbsoluteTimeout).Sub(now)
+		if (ttl <= 0) || (remaining < ttl) {
+			ttl = remaining
+		}
+	}
+	return ttl
+}
+
+// touchInterval returns how often an unchanged session is saved again to keep it alive,
+// so sessions are not saved on every request.
+func (s *Service) touchInterval() time.Duration {
+	if s.config.IdleTimeout <= 0 {
+		return time.Duration(math.MaxInt64)
+	}
+	return min(s.config.IdleTimeout/10, time.Minute)
+}
+
+// cookie returns the session cookie with the value, expiring after maxAge, or deleted if maxAge is negative.
+func (s *Service) cookie(value string, maxAge time.Duration) *http.Cookie {
+	c := &http.Cookie{
+		Name:     s.config.CookieName,
+		Value:    value,
+		Path:     s.config.CookiePath,
+		Domain:   s.config.CookieDomain,
+		Secure:   s.config.Secure,
+		HttpOnly: true,
+		SameSite: s.config.SameSite,
+		MaxAge:   max(int(maxAge/time.Second), 1),
+	}
+	if maxAge < 0 {
+		c.MaxAge = -1
+	}
+	return c
+}
@@ -0,0 +1,362 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/huboh/vara"
+)
+
+// testClock is a clock only moved by tests.
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time { return c.now }
+
+func newTestClock() *testClock {
+	return &testClock{now: time.Unix(1700000000, 0)}
+}
+
+func newTestService(t *testing.T, c Config, store SessionStore, clock *testClock) *Service {
+	t.Helper()
+
+	c.Keys = [][]byte{testKey(1)}
+	s, err := NewService(c, store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	s.now = clock.Now
+	return s
+}
+
+// testStores returns the stores sessions are tested with, using the clock.
+func testStores(t *testing.T, clock *testClock) map[string]SessionStore {
+	t.Helper()
+
+	mem := NewMemoryStore()
+	mem.now = clock.Now
+
+	file, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.now = clock.Now
+
+	return map[string]SessionStore{
+		"cookie":       nil,
+		"memory store": mem,
+		"file store":   file,
+	}
+}
+
+// request handles a request carrying the session cookie, calling fn with its session, and
+// returns the session cookie set on the response, if any.
+func request(t *testing.T, s *Service, cookie *http.Cookie, fn func(*Session)) *http.Cookie {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if cookie != nil {
+		r.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	w := httptest.NewRecorder()
+
+	st := &state{}
+	newInterceptor(s).serve(w, r, st, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess, err := s.access(st, r)
+		if err != nil {
+			t.Fatalf("access() error = %v", err)
+		}
+		fn(sess)
+	}))
+
+	for _, c := range w.Result().Cookies() {
+		if c.Name == s.config.CookieName {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestSessionValues(t *testing.T) {
+	clock := newTestClock()
+
+	for name, store := range testStores(t, clock) {
+		t.Run(name, func(t *testing.T) {
+			s := newTestService(t, NewConfig(), store, clock)
+
+			if c := request(t, s, nil, func(*Session) {}); c != nil {
+				t.Errorf("unchanged new session set cookie %v", c)
+			}
+
+			var id string
+			cookie := request(t, s, nil, func(sess *Session) {
+				id = sess.ID()
+				_ = sess.Set("user", "alice")
+			})
+			if (cookie == nil) || (cookie.MaxAge != int(s.config.IdleTimeout/time.Second)) {
+				t.Fatalf("cookie = %v, want a cookie expiring after the idle timeout", cookie)
+			}
+
+			clock.now = clock.now.Add(time.Second)
+			c := request(t, s, cookie, func(sess *Session) {
+				user, _ := Get[string](sess, "user")
+				if sess.IsNew() || (sess.ID() != id) || (user != "alice") {
+					t.Errorf("session = %s, %q, new: %v, want the saved session", sess.ID(), user, sess.IsNew())
+				}
+			})
+			if c != nil {
+				t.Errorf("unchanged session set cookie %v within the touch interval", c)
+			}
+		})
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []time.Duration // time between requests, the last one after the session expired
+	}{
+		{"idle timeout", []time.Duration{20 * time.Minute, 20 * time.Minute, 30 * time.Minute}},
+		{"absolute timeout", []time.Duration{25 * time.Minute, 25 * time.Minute, 25 * time.Minute, 25 * time.Minute, 25 * time.Minute}},
+	}
+
+	c := NewConfig()
+	c.IdleTimeout, c.AbsoluteTimeout = 30*time.Minute, 2*time.Hour
+
+	for _, tt := range tests {
+		clock := newTestClock()
+
+		for name, store := range testStores(t, clock) {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				s := newTestService(t, c, store, clock)
+
+				var id string
+				cookie := request(t, s, nil, func(sess *Session) {
+					id = sess.ID()
+					_ = sess.Set("user", "alice")
+				})
+
+				for i, step := range tt.steps {
+					clock.now = clock.now.Add(step)
+					expired := i == (len(tt.steps) - 1)
+
+					renewed := request(t, s, cookie, func(sess *Session) {
+						if (sess.ID() == id) == expired {
+							t.Errorf("request #%d: expired: %v, want %v", i, !expired, expired)
+						}
+					})
+					if renewed != nil {
+						cookie = renewed
+					}
+
+					if expired && ((renewed == nil) || (renewed.MaxAge != -1)) {
+						t.Errorf("request #%d: cookie = %v, want the expired cookie deleted", i, renewed)
+					}
+				}
+
+				if store != nil {
+					_, err := store.Load(context.Background(), id)
+					if !errors.Is(err, ErrNotFound) {
+						t.Errorf("Load(expired) error = %v, want %v", err, ErrNotFound)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestSessionRenewID(t *testing.T) {
+	clock := newTestClock()
+	store := NewMemoryStore()
+	store.now = clock.Now
+	s := newTestService(t, NewConfig(), store, clock)
+
+	var id, token string
+	cookie := request(t, s, nil, func(sess *Session) {
+		id = sess.ID()
+		token, _ = newCSRFToken()
+		_ = sess.Set(csrfKey, token)
+		_ = sess.Set("user", "alice")
+	})
+
+	var renewedID string
+	cookie = request(t, s, cookie, func(sess *Session) {
+		err := sess.RenewID()
+		if err != nil {
+			t.Fatalf("RenewID() error = %v", err)
+		}
+		renewedID = sess.ID()
+	})
+
+	if renewedID == id {
+		t.Fatal("RenewID() kept the session's ID")
+	}
+	if _, err := store.Load(context.Background(), id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load(previous ID) error = %v, want %v", err, ErrNotFound)
+	}
+
+	request(t, s, cookie, func(sess *Session) {
+		user, _ := Get[string](sess, "user")
+		renewedToken, _ := Get[string](sess, csrfKey)
+		if (sess.ID() != renewedID) || (user != "alice") {
+			t.Errorf("session = %s, %q, want the renewed session", sess.ID(), user)
+		}
+		if (renewedToken == "") || (renewedToken == token) {
+			t.Errorf("CSRF token = %q, want a new token", renewedToken)
+		}
+	})
+}
+
+func TestSessionDestroy(t *testing.T) {
+	clock := newTestClock()
+	store := NewMemoryStore()
+	store.now = clock.Now
+	s := newTestService(t, NewConfig(), store, clock)
+
+	var id string
+	cookie := request(t, s, nil, func(sess *Session) {
+		id = sess.ID()
+		_ = sess.Set("user", "alice")
+	})
+
+	clock.now = clock.now.Add(time.Hour)
+	cookie = request(t, s, cookie, func(sess *Session) {
+		_ = sess.Destroy()
+		if !sess.CreatedAt().Equal(clock.now) {
+			t.Errorf("CreatedAt() = %v, want the service clock's %v", sess.CreatedAt(), clock.now)
+		}
+	})
+
+	if (cookie == nil) || (cookie.MaxAge != -1) {
+		t.Errorf("cookie = %v, want the session cookie deleted", cookie)
+	}
+	if _, err := store.Load(context.Background(), id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load(destroyed) error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestSessionTamperedCookie(t *testing.T) {
+	clock := newTestClock()
+
+	for name, store := range testStores(t, clock) {
+		t.Run(name, func(t *testing.T) {
+			s := newTestService(t, NewConfig(), store, clock)
+
+			cookie := request(t, s, nil, func(sess *Session) { _ = sess.Set("user", "alice") })
+			cookie.Value = cookie.Value[:len(cookie.Value)-2] + "AA"
+
+			request(t, s, cookie, func(sess *Session) {
+				if !sess.IsNew() || sess.Has("user") {
+					t.Error("a tampered cookie loaded its session")
+				}
+			})
+		})
+	}
+}
+
+func TestSessionCommitBeforeHeader(t *testing.T) {
+	clock := newTestClock()
+	s := newTestService(t, NewConfig(), nil, clock)
+
+	var (
+		r  = httptest.NewRequest(http.MethodGet, "/", nil)
+		w  = httptest.NewRecorder()
+		st = &state{}
+	)
+
+	newInterceptor(s).serve(w, r, st, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess, _ := s.access(st, r)
+		_ = sess.Set("user", "alice")
+		w.WriteHeader(http.StatusCreated)
+
+		// the session was saved with the header, so later changes are not
+		_ = sess.Set("user", "bob")
+	}))
+
+	// the recorder's result holds the header as written
+	res := w.Result()
+	if (res.StatusCode != http.StatusCreated) || (len(res.Cookies()) != 1) {
+		t.Fatalf("response = %d with cookies %v, want a 201 with the session cookie", res.StatusCode, res.Cookies())
+	}
+
+	request(t, s, res.Cookies()[0], func(sess *Session) {
+		if user, _ := Get[string](sess, "user"); user != "alice" {
+			t.Errorf("user = %q, want the value set before the header was written", user)
+		}
+	})
+}
+
+func TestCSRFGuard(t *testing.T) {
+	sess, err := newSession(time.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = sess.Set(csrfKey, "token")
+
+	empty, err := newSession(time.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newRequest := func(header, field string) *http.Request {
+		form := url.Values{}
+		if field != "" {
+			form.Set(CSRFField, field)
+		}
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if header != "" {
+			r.Header.Set(CSRFHeader, header)
+		}
+		return r
+	}
+
+	tests := []struct {
+		name    string
+		sess    *Session
+		req     *http.Request
+		wantErr bool
+	}{
+		{"header", sess, newRequest("token", ""), false},
+		{"form field", sess, newRequest("", "token"), false},
+		{"missing token", sess, newRequest("", ""), true},
+		{"wrong token", sess, newRequest("other", ""), true},
+		{"header overrides form field", sess, newRequest("other", "token"), true},
+		{"session without token", empty, newRequest("token", ""), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyCSRFToken(tt.sess, tt.req)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("verifyCSRFToken() error = %v", err)
+				}
+				return
+			}
+
+			var httpErr *vara.HTTPError
+			if !errors.As(err, &httpErr) || (httpErr.Status != http.StatusForbidden) || !errors.Is(err, ErrInvalidCSRFToken) {
+				t.Errorf("verifyCSRFToken() error = %v, want a 403 Forbidden", err)
+			}
+		})
+	}
+
+	g := NewCSRFGuard(newTestService(t, NewConfig(), nil, newTestClock()))
+	for method, wantErr := range map[string]error{http.MethodGet: nil, http.MethodHead: nil, http.MethodPost: ErrNoSession} {
+		r := httptest.NewRequest(method, "/", nil)
+		ok, err := g.Allow(vara.GuardContext{Http: vara.GuardContextHttp{R: r, W: httptest.NewRecorder()}})
+		if (ok != (wantErr == nil)) || !errors.Is(err, wantErr) {
+			t.Errorf("Allow(%s) = %v, %v, want %v", method, ok, err, wantErr)
+		}
+	}
+}
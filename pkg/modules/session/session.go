@@ -0,0 +1,202 @@
+package session
+
+import (
+	"encoding/json"
+	"maps"
+	"sync"
+	"time"
+)
+
+// Session is the session of a client. Its values are encoded as JSON, and read with [Get].
+//
+// Changes to a session are saved once the handler of the request it was loaded for starts
+// writing the response, or returns.
+type Session struct {
+	mu        sync.RWMutex
+	id        string
+	values    map[string]json.RawMessage
+	createdAt time.Time
+	lastSeen  time.Time
+	isNew     bool
+	modified  bool
+	stale     []string // IDs the session was previously saved under, to delete
+	now       func() time.Time
+}
+
+// newSession returns a new empty session, created at the time of the clock.
+func newSession(now func() time.Time) (*Session, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt := now()
+	return &Session{
+		id:        id,
+		values:    make(map[string]json.RawMessage),
+		createdAt: createdAt,
+		lastSeen:  createdAt,
+		isNew:     true,
+		now:       now,
+	}, nil
+}
+
+// fromRecord returns the session saved under the ID.
+func fromRecord(id string, rec Record, now func() time.Time) *Session {
+	values := rec.Values
+	if values == nil {
+		values = make(map[string]json.RawMessage)
+	}
+
+	return &Session{
+		id:        id,
+		values:    values,
+		createdAt: rec.CreatedAt,
+		lastSeen:  rec.LastSeen,
+		now:       now,
+	}
+}
+
+// ID returns the ID of the session.
+func (s *Session) ID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.id
+}
+
+// IsNew reports if the session was created for the request, rather than loaded.
+func (s *Session) IsNew() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.isNew
+}
+
+// CreatedAt returns the time the session was created.
+func (s *Session) CreatedAt() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.createdAt
+}
+
+// Has reports if the session has a value under the key.
+func (s *Session) Has(key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.values[key]
+	return ok
+}
+
+// Set stores the value under the key. The value must be encodable as JSON.
+func (s *Session) Set(key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[key] = data
+	s.modified = true
+	return nil
+}
+
+// Delete deletes the value under the key.
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.values[key]; ok {
+		delete(s.values, key)
+		s.modified = true
+	}
+}
+
+// RenewID moves the session to a new ID, keeping its values, and regenerates its CSRF token, if
+// any. It must be called when the privileges of the session change, e.g. on login, so an ID or
+// CSRF token fixed by an attacker before then is worthless.
+func (s *Session) RenewID() error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+
+	token, err := newCSRFToken()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.isNew {
+		s.stale = append(s.stale, s.id)
+	}
+	if _, ok := s.values[csrfKey]; ok {
+		s.values[csrfKey] = data
+	}
+	s.id = id
+	s.modified = true
+	return nil
+}
+
+// Destroy deletes the session and its values, e.g. on logout. Values set afterwards are
+// saved to a new session.
+func (s *Session) Destroy() error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.isNew {
+		s.stale = append(s.stale, s.id)
+	}
+	s.id = id
+	s.values = make(map[string]json.RawMessage)
+	s.createdAt = s.now()
+	s.isNew = true
+	s.modified = false
+	return nil
+}
+
+// record returns the record of the session, as saved under its ID.
+func (s *Session) record(now time.Time) Record {
+	return Record{
+		Values:    maps.Clone(s.values),
+		CreatedAt: s.createdAt,
+		LastSeen:  now,
+	}
+}
+
+// Get returns the value stored under the key in the session, decoded into a T. It reports false
+// if the session has no value under the key, or if the value is not a T.
+//
+//	userID, ok := session.Get[string](sess, "user_id")
+func Get[T any](s *Session, key string) (T, bool) {
+	var value T
+	if s == nil {
+		return value, false
+	}
+
+	s.mu.RLock()
+	data, ok := s.values[key]
+	s.mu.RUnlock()
+
+	if !ok || (json.Unmarshal(data, &value) != nil) {
+		var zero T
+		return zero, false
+	}
+	return value, true
+}
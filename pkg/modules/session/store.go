@@ -0,0 +1,112 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often stores drop the sessions that have expired.
+const sweepInterval = time.Minute
+
+// Record is a session as saved in a [SessionStore].
+type Record struct {
+	// Values are the JSON encoded values of the session, by key
+	Values map[string]json.RawMessage `json:"values"`
+
+	// CreatedAt is the time the session was created
+	CreatedAt time.Time `json:"created_at"`
+
+	// LastSeen is the time the session was last used
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// SessionStore stores sessions server side, e.g. in memory, or in a database to share
+// sessions between the instances of an application. The cookies of sessions kept in
+// a store only carry their signed ID.
+type SessionStore interface {
+	// Load returns the session saved under the ID, or ErrNotFound if there is none, or it has expired.
+	Load(ctx context.Context, id string) (Record, error)
+
+	// Save saves the session under the ID until the ttl elapses.
+	Save(ctx context.Context, id string, rec Record, ttl time.Duration) error
+
+	// Delete deletes the session saved under the ID, if any.
+	Delete(ctx context.Context, id string) error
+}
+
+// memoryEntry is a session saved in a MemoryStore.
+type memoryEntry struct {
+	rec       Record
+	expiresAt time.Time
+}
+
+// MemoryStore is an in-memory [SessionStore], keeping sessions on a single instance of an
+// application, until it restarts.
+type MemoryStore struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		now:     time.Now,
+		entries: make(map[string]memoryEntry),
+	}
+}
+
+func (s *MemoryStore) Load(_ context.Context, id string) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	e, ok := s.entries[id]
+	if !ok || !now.Before(e.expiresAt) {
+		return Record{}, ErrNotFound
+	}
+
+	rec := e.rec
+	rec.Values = maps.Clone(rec.Values)
+	return rec, nil
+}
+
+func (s *MemoryStore) Save(_ context.Context, id string, rec Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	rec.Values = maps.Clone(rec.Values)
+	s.entries[id] = memoryEntry{rec: rec, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, id)
+	return nil
+}
+
+// sweep drops the sessions that have expired, at most once per sweep interval.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for id, e := range s.entries {
+		if !now.Before(e.expiresAt) {
+			delete(s.entries, id)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package throttle
+
+import (
+	"math"
+	"time"
+)
+
+// Algorithm is the algorithm requests are limited with.
+type Algorithm string
+
+// supported Algorithm
+const (
+	// TokenBucket allows bursts of up to Limit requests, refilling the bucket at Limit requests per Window
+	TokenBucket = Algorithm("token_bucket")
+
+	// SlidingWindow allows Limit requests in any Window, estimating the requests of the sliding
+	// window from the counts of the current and previous fixed windows
+	SlidingWindow = Algorithm("sliding_window")
+)
+
+// tokenBucket is the state of a key limited with the TokenBucket algorithm.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) take(now time.Time, p Policy) Result {
+	var (
+		capacity = float64(p.Limit)
+		rate     = capacity / p.Window.Seconds()
+	)
+
+	if b.last.IsZero() {
+		b.tokens = capacity
+	} else {
+		b.tokens = math.Min(capacity, b.tokens+(now.Sub(b.last).Seconds()*rate))
+	}
+	b.last = now
+
+	res := Result{Limit: p.Limit}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((capacity - b.tokens) / rate)
+	return res
+}
+
+// expired reports if the bucket is full again, so its state can be dropped.
+func (b *tokenBucket) expired(now time.Time, p Policy) bool {
+	return now.Sub(b.last) >= p.Window
+}
+
+// slidingWindow is the state of a key limited with the SlidingWindow algorithm.
+type slidingWindow struct {
+	start time.Time
+	prev  int
+	curr  int
+}
+
+func (w *slidingWindow) take(now time.Time, p Policy) Result {
+	if w.start.IsZero() {
+		w.start = now
+	}
+
+	// moves to the fixed window holding now
+	if elapsed := now.Sub(w.start); elapsed >= p.Window {
+		windows := elapsed / p.Window
+		if windows == 1 {
+			w.prev = w.curr
+		} else {
+			w.prev = 0
+		}
+		w.curr = 0
+		w.start = w.start.Add(windows * p.Window)
+	}
+
+	var (
+		progress  = float64(now.Sub(w.start)) / float64(p.Window)
+		estimated = (float64(w.prev) * (1 - progress)) + float64(w.curr)
+		res       = Result{Limit: p.Limit, Reset: w.start.Add(p.Window).Sub(now)}
+	)
+
+	if (estimated + 1) <= float64(p.Limit) {
+		w.curr++
+		estimated++
+		res.Allowed = true
+	} else if (w.curr + 1) > p.Limit {
+		res.RetryAfter = res.Reset
+	} else {
+		// waits until enough of the previous window's requests slid out
+		needed := 1 - (float64(p.Limit-w.curr-1) / float64(w.prev))
+		res.RetryAfter = w.start.Add(time.Duration(needed * float64(p.Window))).Sub(now)
+	}
+
+	res.Remaining = max(0, p.Limit-int(math.Ceil(estimated)))
+	return res
+}
+
+// expired reports if the window's requests all slid out, so its state can be dropped.
+func (w *slidingWindow) expired(now time.Time, p Policy) bool {
+	return now.Sub(w.start) >= (2 * p.Window)
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
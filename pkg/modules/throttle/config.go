@@ -0,0 +1,10 @@
+package throttle
+
+type Config struct {
+	// Default is the policy limiting the requests to routes without a [Limit],
+	// or the zero value to only limit the requests to routes with one
+	Default Policy
+
+	// FailOpen specifies if requests are allowed when the store fails, rather than rejected
+	FailOpen bool
+}
@@ -0,0 +1,103 @@
+package throttle
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/huboh/vara"
+)
+
+// Guard limits the requests to routes with the policy set by the [Limit] metadata of the route,
+// or the module's default policy. Requests over the limit are rejected with a 429 Too Many
+// Requests. Responses carry the RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and
+// RateLimit-Policy headers, and rejections a Retry-After header. Invalid policies fail
+// the registration of their routes.
+type Guard struct {
+	service   *Service
+	logger    *slog.Logger
+	reflector *vara.Reflector
+}
+
+// NewGuard returns a guard limiting requests with the service. It is a [vara.GuardConstructor],
+// and can be applied to every route with [vara.GlobalGuard].
+func NewGuard(s *Service, r *vara.Reflector, l *slog.Logger) *Guard {
+	return &Guard{
+		logger:    l,
+		service:   s,
+		reflector: r,
+	}
+}
+
+func (g *Guard) Allow(gCtx vara.GuardContext) (bool, error) {
+	p, ok := g.policy(gCtx.Context())
+	if !ok {
+		return true, nil
+	}
+
+	res, err := g.service.Take(gCtx.Http.R, p)
+	if err != nil {
+		if g.service.config.FailOpen {
+			g.logger.Warn("could not throttle request", "error", err)
+			return true, nil
+		}
+		return false, err
+	}
+
+	setHeaders(gCtx.Http.W.Header(), p, res)
+	if !res.Allowed {
+		return false, vara.ErrTooManyRequests.WithHeader("Retry-After", formatSeconds(res.RetryAfter))
+	}
+
+	return true, nil
+}
+
+// CheckRoute validates the policy limiting the requests to the route when it is registered.
+func (g *Guard) CheckRoute(route vara.RouteInfo) error {
+	p, ok := g.policy(&vara.Context{Route: route})
+	if !ok {
+		return nil
+	}
+
+	_, err := p.withDefaults()
+	return err
+}
+
+// policy returns the policy limiting the requests to the route of the context,
+// and reports false if its requests are not limited.
+func (g *Guard) policy(c *vara.Context) (Policy, bool) {
+	skip, _ := vara.GetAllAndOverride[bool](g.reflector, c, metadataSkip)
+	if skip {
+		return Policy{}, false
+	}
+
+	p, ok := vara.GetAllAndOverride[Policy](g.reflector, c, metadataPolicy)
+	if !ok {
+		p = g.service.config.Default
+		if p.Limit == 0 {
+			return Policy{}, false
+		}
+	}
+	if (p.Name == "") && (c != nil) {
+		// limits each route separately
+		p.Name = c.Route.Method + " " + c.Route.Path
+	}
+
+	return p, true
+}
+
+// setHeaders sets the RateLimit headers of the result.
+func setHeaders(h http.Header, p Policy, res Result) {
+	h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	h.Set("RateLimit-Reset", formatSeconds(res.Reset))
+	h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%s", p.Limit, formatSeconds(p.Window)))
+}
+
+// formatSeconds returns the duration in whole seconds, rounded up.
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
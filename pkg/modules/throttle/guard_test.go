@@ -0,0 +1,131 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/huboh/vara"
+)
+
+// failingStore is a store failing to take any request.
+type failingStore struct{}
+
+func (failingStore) Take(_ context.Context, _ string, _ Policy) (Result, error) {
+	return Result{}, errors.New("store unavailable")
+}
+
+func newTestGuard(c Config, s Store) *Guard {
+	return NewGuard(NewService(c, s), &vara.Reflector{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestGuard(t *testing.T) {
+	var (
+		now   = time.Unix(1700000000, 0)
+		store = NewMemoryStore()
+		g     = newTestGuard(Config{Default: Policy{Limit: 2, Window: time.Minute}}, store)
+	)
+	store.now = func() time.Time { return now }
+
+	// a request is refilled every 30s
+	tests := []struct {
+		at             time.Duration
+		wantAllowed    bool
+		wantRetryAfter string
+		wantHeaders    map[string]string
+	}{
+		{0, true, "", map[string]string{"RateLimit-Limit": "2", "RateLimit-Remaining": "1", "RateLimit-Reset": "30", "RateLimit-Policy": "2;w=60"}},
+		{0, true, "", map[string]string{"RateLimit-Remaining": "0", "RateLimit-Reset": "60"}},
+		{0, false, "30", map[string]string{"RateLimit-Remaining": "0", "RateLimit-Reset": "60"}},
+		{10 * time.Second, false, "20", map[string]string{"RateLimit-Remaining": "0", "RateLimit-Reset": "50"}},
+	}
+
+	for i, tt := range tests {
+		now = now.Add(tt.at)
+
+		var (
+			w = httptest.NewRecorder()
+			r = httptest.NewRequest(http.MethodGet, "/", nil)
+		)
+
+		ok, err := g.Allow(vara.GuardContext{Http: vara.GuardContextHttp{R: r, W: w}})
+		if ok != tt.wantAllowed {
+			t.Errorf("request #%d: Allow() = %v, %v, want %v", i, ok, err, tt.wantAllowed)
+		}
+
+		if !tt.wantAllowed {
+			var httpErr *vara.HTTPError
+			if !errors.As(err, &httpErr) || (httpErr.Status != http.StatusTooManyRequests) {
+				t.Fatalf("request #%d: Allow() error = %v, want a 429 Too Many Requests", i, err)
+			}
+			if got := httpErr.Header.Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("request #%d: Retry-After = %q, want %q", i, got, tt.wantRetryAfter)
+			}
+		} else if err != nil {
+			t.Errorf("request #%d: Allow() error = %v", i, err)
+		}
+
+		for name, want := range tt.wantHeaders {
+			if got := w.Header().Get(name); got != want {
+				t.Errorf("request #%d: %s = %q, want %q", i, name, got, want)
+			}
+		}
+	}
+}
+
+func TestGuardStoreFailure(t *testing.T) {
+	p := Policy{Limit: 1, Window: time.Second}
+
+	tests := []struct {
+		name     string
+		failOpen bool
+	}{
+		{"fail closed", false},
+		{"fail open", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGuard(Config{Default: p, FailOpen: tt.failOpen}, failingStore{})
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			ok, err := g.Allow(vara.GuardContext{Http: vara.GuardContextHttp{R: r, W: httptest.NewRecorder()}})
+			if (ok != tt.failOpen) || ((err == nil) == !tt.failOpen) {
+				t.Errorf("Allow() = %v, %v, want %v", ok, err, tt.failOpen)
+			}
+		})
+	}
+}
+
+func TestGuardCheckRoute(t *testing.T) {
+	g := newTestGuard(Config{}, NewMemoryStore())
+
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr bool
+	}{
+		{"valid", Policy{Limit: 1, Window: time.Second, Algorithm: SlidingWindow}, false},
+		{"no limit", Policy{Window: time.Second}, true},
+		{"no window", Policy{Limit: 1}, true},
+		{"unknown algorithm", Policy{Limit: 1, Window: time.Second, Algorithm: "leaky_bucket"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := vara.RouteInfo{
+				RouteConfig: vara.RouteConfig{Metadata: vara.Metadata{Limit(tt.policy)}},
+			}
+
+			err := g.CheckRoute(route)
+			if tt.wantErr != errors.Is(err, ErrInvalidPolicy) {
+				t.Errorf("CheckRoute() error = %v, want an error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
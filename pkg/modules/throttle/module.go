@@ -0,0 +1,48 @@
+package throttle
+
+import (
+	"github.com/huboh/vara"
+)
+
+// Module limits the requests to routes applying [NewGuard]. Zero-valued fields use an in-memory
+// store, and only limit the requests to routes with a [Limit].
+type Module struct {
+	// Store is the store the state of limited clients is kept in
+	Store Store
+
+	// Default is the policy limiting the requests to routes without a [Limit]
+	Default Policy
+
+	// FailOpen specifies if requests are allowed when the store fails, rather than rejected
+	FailOpen bool
+}
+
+func (m *Module) Config() *vara.ModuleConfig {
+	return &vara.ModuleConfig{
+		ExportConstructors:   []vara.ProviderConstructor{NewService},
+		ProviderConstructors: []vara.ProviderConstructor{m.newConfig, m.newStore, NewService},
+	}
+}
+
+// newConfig returns the config of the module's fields.
+func (m *Module) newConfig() (Config, error) {
+	if m.Default.Limit != 0 {
+		_, err := m.Default.withDefaults()
+		if err != nil {
+			return Config{}, err
+		}
+	}
+
+	return Config{
+		Default:  m.Default,
+		FailOpen: m.FailOpen,
+	}, nil
+}
+
+// newStore returns the module's store, or an in-memory store if it has none.
+func (m *Module) newStore() Store {
+	if m.Store != nil {
+		return m.Store
+	}
+	return NewMemoryStore()
+}
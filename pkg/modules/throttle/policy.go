@@ -0,0 +1,117 @@
+package throttle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/huboh/vara"
+)
+
+// metadata keys of the policies enforced by the Guard
+const (
+	metadataPolicy = "throttle.policy"
+	metadataSkip   = "throttle.skip"
+)
+
+var (
+	// ErrInvalidPolicy indicates a policy has no limit or window, or an unknown algorithm
+	ErrInvalidPolicy = errors.New("invalid throttle policy")
+)
+
+// KeyFunc returns the key the requests of a client are limited by.
+type KeyFunc func(r *http.Request) string
+
+// Policy limits the requests of each client to routes.
+type Policy struct {
+	// Name is the name of the limit the policy's routes share, or
+	// empty to limit the requests of each route separately
+	Name string
+
+	// Limit is the number of requests allowed in a window
+	Limit int
+
+	// Window is the duration requests are limited over
+	Window time.Duration
+
+	// Algorithm is the algorithm requests are limited with, [TokenBucket] by default
+	Algorithm Algorithm
+
+	// Key is the key the requests of a client are limited by, [ByIP] by default
+	Key KeyFunc
+}
+
+// withDefaults returns the policy with the default algorithm and key set.
+func (p Policy) withDefaults() (Policy, error) {
+	if (p.Limit < 1) || (p.Window <= 0) {
+		return Policy{}, fmt.Errorf("%w: %d requests per %s", ErrInvalidPolicy, p.Limit, p.Window)
+	}
+	switch p.Algorithm {
+	case "":
+		p.Algorithm = TokenBucket
+	case TokenBucket, SlidingWindow:
+	default:
+		return Policy{}, fmt.Errorf("%w: unknown algorithm %q", ErrInvalidPolicy, p.Algorithm)
+	}
+	if p.Key == nil {
+		p.Key = ByIP
+	}
+	return p, nil
+}
+
+// Limit limits the requests to routes with the policy, overriding the module's default policy.
+// Route metadata overrides controller metadata, which overrides module metadata.
+func Limit(p Policy) vara.MetadataEntry {
+	return vara.SetMetadata(metadataPolicy, p)
+}
+
+// Skip exempts routes from the module's default policy and the policies of their controller and module.
+func Skip() vara.MetadataEntry {
+	return vara.SetMetadata(metadataSkip, true)
+}
+
+// ByIP limits the requests of each client IP address. Behind a proxy, the request's
+// RemoteAddr should be set to the client's address before it is routed.
+func ByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return "ip:" + r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// ByAPIKey returns a [KeyFunc] limiting the requests of each API key read from the header,
+// and the requests without one by IP address. Keys are hashed so stores never hold them.
+func ByAPIKey(header string) KeyFunc {
+	return func(r *http.Request) string {
+		key := r.Header.Get(header)
+		if key == "" {
+			return ByIP(r)
+		}
+
+		digest := sha256.Sum256([]byte(key))
+		return "key:" + hex.EncodeToString(digest[:])
+	}
+}
+
+// ByUser returns a [KeyFunc] limiting the requests of each user, identified by the user func,
+// and the requests of anonymous users, for which it returns an empty string, by IP address.
+// The guard authenticating requests must run first, e.g. with the jwt module:
+//
+//	throttle.ByUser(func(r *http.Request) string {
+//		claims, _ := jwt.ClaimsFromRequest(r)
+//		return claims.Subject()
+//	})
+func ByUser(user KeyFunc) KeyFunc {
+	return func(r *http.Request) string {
+		id := user(r)
+		if id == "" {
+			return ByIP(r)
+		}
+		return "user:" + id
+	}
+}
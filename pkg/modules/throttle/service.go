@@ -0,0 +1,28 @@
+package throttle
+
+import (
+	"net/http"
+)
+
+type Service struct {
+	store  Store
+	config Config
+}
+
+func NewService(c Config, s Store) *Service {
+	return &Service{
+		store:  s,
+		config: c,
+	}
+}
+
+// Take takes the request from the limit of its client under the policy, as keyed by the policy's
+// key func. Limits are shared by the requests taken with the same policy name.
+func (s *Service) Take(r *http.Request, p Policy) (Result, error) {
+	p, err := p.withDefaults()
+	if err != nil {
+		return Result{}, err
+	}
+
+	return s.store.Take(r.Context(), (p.Name + ":" + p.Key(r)), p)
+}
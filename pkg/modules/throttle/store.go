@@ -0,0 +1,111 @@
+package throttle
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often the MemoryStore drops the state of keys that are no longer limited.
+const sweepInterval = time.Minute
+
+// Result is the result of taking a request from a key's limit.
+type Result struct {
+	// Allowed reports if the request is within the limit
+	Allowed bool
+
+	// Limit is the number of requests allowed in the policy's window
+	Limit int
+
+	// Remaining is the number of requests remaining in the current window
+	Remaining int
+
+	// Reset is the time until the limit is fully available again
+	Reset time.Duration
+
+	// RetryAfter is the time until a denied request would be allowed
+	RetryAfter time.Duration
+}
+
+// Store stores the state of the keys requests are limited by, e.g. in memory,
+// or in Redis to share limits between the instances of an application.
+type Store interface {
+	// Take takes a request from the limit of the key, as defined by the policy.
+	Take(ctx context.Context, key string, p Policy) (Result, error)
+}
+
+// limiter is the state of a key limited with an algorithm.
+type limiter interface {
+	take(now time.Time, p Policy) Result
+	expired(now time.Time, p Policy) bool
+}
+
+// entry is the state of a key, with the policy it is limited by.
+type entry struct {
+	limiter limiter
+	policy  Policy
+}
+
+// MemoryStore is an in-memory [Store], limiting requests to a single instance of an application.
+type MemoryStore struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	entries   map[string]*entry
+	lastSweep time.Time
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		now:     time.Now,
+		entries: make(map[string]*entry),
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, p Policy) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	e, ok := s.entries[key]
+	if !ok || (e.policy.Algorithm != p.Algorithm) {
+		l, err := newLimiter(p.Algorithm)
+		if err != nil {
+			return Result{}, err
+		}
+		e = &entry{limiter: l}
+		s.entries[key] = e
+	}
+	e.policy = p
+
+	return e.limiter.take(now, p), nil
+}
+
+// sweep drops the state of the keys that are no longer limited, at most once per sweep interval.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, e := range s.entries {
+		if e.limiter.expired(now, e.policy) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// newLimiter returns the state of a key limited with the algorithm.
+func newLimiter(a Algorithm) (limiter, error) {
+	switch a {
+	case TokenBucket:
+		return &tokenBucket{}, nil
+	case SlidingWindow:
+		return &slidingWindow{}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown algorithm %q", ErrInvalidPolicy, a)
+	}
+}
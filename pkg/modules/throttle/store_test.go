@@ -0,0 +1,115 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// take is a request taken from a key's limit at an offset from the start of a test.
+type take struct {
+	at   time.Duration
+	want Result
+}
+
+func runTakes(t *testing.T, p Policy, takes []take) {
+	t.Helper()
+
+	var (
+		start = time.Unix(1700000000, 0)
+		now   time.Time
+		s     = NewMemoryStore()
+	)
+	s.now = func() time.Time { return now }
+
+	for i, tk := range takes {
+		now = start.Add(tk.at)
+
+		got, err := s.Take(context.Background(), "key", p)
+		if err != nil {
+			t.Fatalf("take #%d: Take() error = %v", i, err)
+		}
+		if got != tk.want {
+			t.Errorf("take #%d at %s: Take() = %+v, want %+v", i, tk.at, got, tk.want)
+		}
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	p := Policy{Limit: 2, Window: 2 * time.Second, Algorithm: TokenBucket}
+
+	runTakes(t, p, []take{
+		// the bucket starts full
+		{0, Result{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}},
+		{0, Result{Allowed: true, Limit: 2, Remaining: 0, Reset: 2 * time.Second}},
+		{0, Result{Limit: 2, Remaining: 0, Reset: 2 * time.Second, RetryAfter: time.Second}},
+
+		// refills at a request per second
+		{500 * time.Millisecond, Result{Limit: 2, Remaining: 0, Reset: 1500 * time.Millisecond, RetryAfter: 500 * time.Millisecond}},
+		{1500 * time.Millisecond, Result{Allowed: true, Limit: 2, Remaining: 0, Reset: 1500 * time.Millisecond}},
+
+		// never holds more than its capacity
+		{10 * time.Second, Result{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}},
+	})
+}
+
+func TestSlidingWindow(t *testing.T) {
+	p := Policy{Limit: 4, Window: 10 * time.Second, Algorithm: SlidingWindow}
+
+	runTakes(t, p, []take{
+		{0, Result{Allowed: true, Limit: 4, Remaining: 3, Reset: 10 * time.Second}},
+		{0, Result{Allowed: true, Limit: 4, Remaining: 2, Reset: 10 * time.Second}},
+		{0, Result{Allowed: true, Limit: 4, Remaining: 1, Reset: 10 * time.Second}},
+		{0, Result{Allowed: true, Limit: 4, Remaining: 0, Reset: 10 * time.Second}},
+
+		// the current window is full, so the next one must start
+		{0, Result{Limit: 4, Remaining: 0, Reset: 10 * time.Second, RetryAfter: 10 * time.Second}},
+
+		// 80% of the previous window's 4 requests are estimated in the sliding window,
+		// until a quarter of the window elapsed and only 3 of them are
+		{12 * time.Second, Result{Limit: 4, Remaining: 0, Reset: 8 * time.Second, RetryAfter: 500 * time.Millisecond}},
+		{12500 * time.Millisecond, Result{Allowed: true, Limit: 4, Remaining: 0, Reset: 7500 * time.Millisecond}},
+
+		// the requests of windows older than the previous one all slid out
+		{35 * time.Second, Result{Allowed: true, Limit: 4, Remaining: 3, Reset: 5 * time.Second}},
+	})
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	var (
+		now = time.Unix(1700000000, 0)
+		s   = NewMemoryStore()
+		p   = Policy{Limit: 1, Window: time.Second, Algorithm: TokenBucket}
+	)
+	s.now = func() time.Time { return now }
+
+	_, err := s.Take(context.Background(), "key", p)
+	if err != nil {
+		t.Fatalf("Take() error = %v", err)
+	}
+
+	now = now.Add(sweepInterval)
+	_, err = s.Take(context.Background(), "other", p)
+	if err != nil {
+		t.Fatalf("Take() error = %v", err)
+	}
+
+	if _, ok := s.entries["key"]; ok {
+		t.Error("the state of a refilled key was not dropped")
+	}
+}
+
+func TestInvalidAlgorithm(t *testing.T) {
+	p := Policy{Limit: 1, Window: time.Second, Algorithm: "leaky_bucket"}
+
+	_, err := p.withDefaults()
+	if !errors.Is(err, ErrInvalidPolicy) {
+		t.Errorf("withDefaults() error = %v, want %v", err, ErrInvalidPolicy)
+	}
+
+	_, err = NewMemoryStore().Take(context.Background(), "key", p)
+	if !errors.Is(err, ErrInvalidPolicy) {
+		t.Errorf("Take() error = %v, want %v", err, ErrInvalidPolicy)
+	}
+}
@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/huboh/vara/pkg/modules/config"
+)
+
+// ExporterType is the type of exporter spans are exported with.
+type ExporterType string
+
+// recognized ExporterType
+const (
+	ExporterOTLP   = ExporterType("otlp")
+	ExporterNone   = ExporterType("none")
+	ExporterStdout = ExporterType("stdout")
+)
+
+// env keys the Config is read from, as defined by the OpenTelemetry specification
+const (
+	EnvExporter     = "OTEL_TRACES_EXPORTER"
+	EnvServiceName  = "OTEL_SERVICE_NAME"
+	EnvOTLPEndpoint = "OTEL_EXPORTER_OTLP_ENDPOINT"
+)
+
+var (
+	// ErrInvalidExporter indicates the exporter type is not recognized
+	ErrInvalidExporter = errors.New("invalid traces exporter")
+)
+
+type Config struct {
+	// Exporter is the type of exporter spans are exported with
+	Exporter ExporterType
+
+	// ServiceName is the name of the service spans are exported for
+	ServiceName string
+
+	// OTLPEndpoint is the base URL of the OpenTelemetry collector
+	OTLPEndpoint string
+}
+
+// NewConfig returns the tracing config read from the OTEL_TRACES_EXPORTER, OTEL_SERVICE_NAME and
+// OTEL_EXPORTER_OTLP_ENDPOINT env keys. Spans are exported to the collector when an endpoint is
+// set, and discarded otherwise.
+func NewConfig(c *config.Service) (Config, error) {
+	cfg := Config{
+		Exporter:     ExporterNone,
+		ServiceName:  "vara",
+		OTLPEndpoint: c.Get(EnvOTLPEndpoint),
+	}
+
+	if cfg.OTLPEndpoint != "" {
+		cfg.Exporter = ExporterOTLP
+	}
+	if name, ok := c.Lookup(EnvServiceName); ok {
+		cfg.ServiceName = name
+	}
+	if exporter, ok := c.Lookup(EnvExporter); ok {
+		cfg.Exporter = ExporterType(strings.ToLower(exporter))
+	}
+
+	switch cfg.Exporter {
+	case ExporterNone, ExporterStdout:
+	case ExporterOTLP:
+		if cfg.OTLPEndpoint == "" {
+			return Config{}, fmt.Errorf("%s must be set to export spans with otlp", EnvOTLPEndpoint)
+		}
+	default:
+		return Config{}, fmt.Errorf("%s: %w: %q", EnvExporter, ErrInvalidExporter, cfg.Exporter)
+	}
+
+	return cfg, nil
+}
+
+// NewExporter returns the exporter of the configured type, logging the spans it fails to export with l.
+func NewExporter(c Config, l *slog.Logger) Exporter {
+	switch c.Exporter {
+	case ExporterOTLP:
+		return NewOTLPExporter(OTLPConfig{Endpoint: c.OTLPEndpoint, ServiceName: c.ServiceName, Logger: l})
+	case ExporterStdout:
+		return NewStdoutExporter(os.Stdout)
+	default:
+		return NoopExporter{}
+	}
+}
@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Exporter exports ended spans, e.g. to a tracing backend.
+type Exporter interface {
+	// ExportSpans exports the spans. It is called once for every ended span.
+	ExportSpans(ctx context.Context, spans []SpanData) error
+
+	// Shutdown flushes the spans not yet exported and releases the exporter's resources.
+	Shutdown(ctx context.Context) error
+}
+
+// NoopExporter discards every span.
+type NoopExporter struct{}
+
+func (NoopExporter) ExportSpans(context.Context, []SpanData) error { return nil }
+
+func (NoopExporter) Shutdown(context.Context) error { return nil }
+
+// InMemoryExporter keeps the exported spans in memory, e.g. for tests.
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+func (e *InMemoryExporter) ExportSpans(_ context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *InMemoryExporter) Shutdown(context.Context) error { return nil }
+
+// Spans returns the spans exported so far, in the order they ended.
+func (e *InMemoryExporter) Spans() []SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]SpanData(nil), e.spans...)
+}
+
+// Reset discards the spans exported so far.
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = nil
+}
+
+// StdoutExporter writes every span as a line of JSON.
+type StdoutExporter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewStdoutExporter returns an exporter writing spans to w, e.g. os.Stdout.
+func NewStdoutExporter(w io.Writer) *StdoutExporter {
+	return &StdoutExporter{
+		encoder: json.NewEncoder(w),
+	}
+}
+
+func (e *StdoutExporter) ExportSpans(_ context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, span := range spans {
+		err := e.encoder.Encode(span)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *StdoutExporter) Shutdown(context.Context) error { return nil }
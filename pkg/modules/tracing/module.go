@@ -0,0 +1,43 @@
+package tracing
+
+import (
+	"log/slog"
+
+	"github.com/huboh/vara"
+	"github.com/huboh/vara/pkg/modules/config"
+	"github.com/huboh/vara/pkg/modules/event"
+)
+
+// Module traces the requests handled by every route and the events handled by the
+// event service. Spans are exported as configured by [NewConfig], unless Exporter is set.
+type Module struct {
+	// Exporter is the exporter spans are exported with, e.g. an [InMemoryExporter] in tests
+	Exporter Exporter
+}
+
+func (m *Module) Config() *vara.ModuleConfig {
+	return &vara.ModuleConfig{
+		Imports:            []vara.Module{&config.Module{}},
+		ExportConstructors: []vara.ProviderConstructor{NewService},
+		ProviderConstructors: []vara.ProviderConstructor{
+			m.newExporter,
+			NewService,
+			vara.GlobalRouteObserver(newRouteObserver),
+			event.Observe(newEventObserver),
+		},
+	}
+}
+
+// newExporter returns the module's exporter, or the exporter configured by [NewConfig] if it has none.
+func (m *Module) newExporter(c *config.Service, l *slog.Logger) (Exporter, error) {
+	if m.Exporter != nil {
+		return m.Exporter, nil
+	}
+
+	cfg, err := NewConfig(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewExporter(cfg, l), nil
+}
@@ -0,0 +1,84 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/huboh/vara"
+)
+
+// routeObserver starts a span for every request handled by a route,
+// with child spans for the interceptors and guards handling it.
+type routeObserver struct {
+	tracing *Service
+}
+
+func newRouteObserver(s *Service) *routeObserver {
+	return &routeObserver{
+		tracing: s,
+	}
+}
+
+func (o *routeObserver) ObserveRequest(r *http.Request, route vara.RouteInfo) (context.Context, func(int)) {
+	ctx, span := o.tracing.Start(
+		Extract(r.Context(), r.Header),
+		route.Method+" "+route.Path,
+		WithKind(SpanKindServer),
+		WithAttributes(
+			Attr("http.request.method", r.Method),
+			Attr("http.route", route.Path),
+			Attr("url.path", r.URL.Path),
+		),
+	)
+
+	return ctx, func(status int) {
+		span.SetAttributes(Attr("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(StatusError, http.StatusText(status))
+		}
+		span.End()
+	}
+}
+
+func (o *routeObserver) ObserveInterceptor(ctx context.Context, _ vara.RouteInfo, i vara.Interceptor) (context.Context, func()) {
+	ctx, span := o.tracing.Start(ctx, fmt.Sprintf("interceptor %T", i))
+	return ctx, span.End
+}
+
+func (o *routeObserver) ObserveGuard(ctx context.Context, _ vara.RouteInfo, g vara.Guard) (context.Context, func(bool, error)) {
+	ctx, span := o.tracing.Start(ctx, fmt.Sprintf("guard %T", g))
+	return ctx, func(allowed bool, err error) {
+		span.SetAttributes(Attr("guard.allowed", allowed))
+		span.RecordError(err)
+		span.End()
+	}
+}
+
+// eventObserver starts a span for every event emitted by the event
+// service, with child spans for the listeners handling it.
+type eventObserver struct {
+	tracing *Service
+}
+
+func newEventObserver(s *Service) *eventObserver {
+	return &eventObserver{
+		tracing: s,
+	}
+}
+
+func (o *eventObserver) ObserveEmit(ctx context.Context, evt string) (context.Context, func(error)) {
+	ctx, span := o.tracing.Start(ctx, "emit "+evt, WithKind(SpanKindProducer), WithAttributes(Attr("event.name", evt)))
+	return ctx, func(err error) {
+		span.RecordError(err)
+		span.End()
+	}
+}
+
+func (o *eventObserver) ObserveListener(ctx context.Context, evt string) (context.Context, func(error)) {
+	ctx, span := o.tracing.Start(ctx, "listener "+evt, WithKind(SpanKindConsumer), WithAttributes(Attr("event.name", evt)))
+	return ctx, func(err error) {
+		span.RecordError(err)
+		span.End()
+	}
+}
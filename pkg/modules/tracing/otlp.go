@@ -0,0 +1,336 @@
+package tracing
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// otlpTracesPath is the path of the OTLP/HTTP traces endpoint.
+const otlpTracesPath = "/v1/traces"
+
+// instrumentationScope is the name of the instrumentation scope spans are exported under.
+const instrumentationScope = "github.com/huboh/vara/pkg/modules/tracing"
+
+var (
+	// ErrExporterShutdown indicates the exporter was shut down
+	ErrExporterShutdown = errors.New("exporter is shut down")
+)
+
+// OTLPConfig configures an [OTLPExporter].
+type OTLPConfig struct {
+	// Endpoint is the base URL of the collector, e.g. "http://localhost:4318"
+	Endpoint string
+
+	// ServiceName is the name of the service spans are exported for
+	ServiceName string
+
+	// Headers are additional headers sent with every export request, e.g. for authentication
+	Headers map[string]string
+
+	// Client is the client export requests are sent with. http.DefaultClient is used if nil
+	Client *http.Client
+
+	// BatchSize is the number of spans buffered before they are exported. Defaults to 512
+	BatchSize int
+
+	// BatchTimeout is the longest time spans are buffered before they are exported. Defaults to 5s
+	BatchTimeout time.Duration
+
+	// MaxQueueSize is the most spans buffered while they wait to be exported, e.g. while the
+	// collector is unreachable. The oldest spans are dropped once it is reached. Defaults to 2048
+	MaxQueueSize int
+
+	// Logger logs the spans that could not be exported or were dropped. slog.Default() is used if nil
+	Logger *slog.Logger
+}
+
+// OTLPExporter exports spans in batches to an OpenTelemetry collector,
+// using the OTLP/HTTP protocol with JSON encoding.
+type OTLPExporter struct {
+	config   OTLPConfig
+	mu       sync.Mutex
+	buffer   []SpanData
+	dropped  int
+	flushCh  chan struct{}
+	stopCh   chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+	isClosed bool
+}
+
+// NewOTLPExporter returns an exporter sending spans to the collector at c.Endpoint.
+func NewOTLPExporter(c OTLPConfig) *OTLPExporter {
+	c.Client = cmp.Or(c.Client, http.DefaultClient)
+	c.BatchSize = cmp.Or(c.BatchSize, 512)
+	c.BatchTimeout = cmp.Or(c.BatchTimeout, 5*time.Second)
+	c.MaxQueueSize = max(cmp.Or(c.MaxQueueSize, 2048), c.BatchSize)
+	c.Logger = cmp.Or(c.Logger, slog.Default())
+
+	e := &OTLPExporter{
+		config:  c,
+		flushCh: make(chan struct{}, 1),
+		stopCh:  make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go e.run()
+
+	return e
+}
+
+// ExportSpans buffers the spans, which are exported once the batch is full or times out. The
+// oldest buffered spans are dropped if the queue is full.
+func (e *OTLPExporter) ExportSpans(_ context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.isClosed {
+		return ErrExporterShutdown
+	}
+
+	e.buffer = append(e.buffer, spans...)
+	if n := len(e.buffer) - e.config.MaxQueueSize; n > 0 {
+		e.buffer = slices.Delete(e.buffer, 0, n)
+		e.dropped += n
+	}
+
+	if len(e.buffer) >= e.config.BatchSize {
+		select {
+		case e.flushCh <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Shutdown exports the buffered spans and stops the exporter.
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	e.stopOnce.Do(func() {
+		e.mu.Lock()
+		e.isClosed = true
+		e.mu.Unlock()
+
+		close(e.stopCh)
+	})
+
+	select {
+	case <-e.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return e.flush(ctx)
+}
+
+// run exports the buffered spans whenever the batch is full or times out, until the exporter stops.
+func (e *OTLPExporter) run() {
+	defer close(e.stopped)
+
+	ticker := time.NewTicker(e.config.BatchTimeout)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.stopCh:
+			return
+		case <-ticker.C:
+		case <-e.flushCh:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), e.config.BatchTimeout)
+		err := e.flush(ctx)
+		if err != nil {
+			e.config.Logger.Warn("could not export spans", "error", err)
+		}
+		cancel()
+	}
+}
+
+// flush sends the buffered spans to the collector, and logs the spans dropped since the last flush.
+func (e *OTLPExporter) flush(ctx context.Context) error {
+	e.mu.Lock()
+	spans, dropped := e.buffer, e.dropped
+	e.buffer, e.dropped = nil, 0
+	e.mu.Unlock()
+
+	if dropped > 0 {
+		e.config.Logger.Warn("dropped spans, the export queue is full", "spans", dropped)
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(e.newRequest(spans))
+	if err != nil {
+		return err
+	}
+
+	url := strings.TrimSuffix(e.config.Endpoint, "/") + otlpTracesPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	res, err := e.config.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not export %d spans: %w", len(spans), err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if (res.StatusCode < 200) || (res.StatusCode > 299) {
+		return fmt.Errorf("could not export %d spans: collector responded with %s", len(spans), res.Status)
+	}
+
+	return nil
+}
+
+// newRequest builds an OTLP export request for the spans.
+func (e *OTLPExporter) newRequest(spans []SpanData) otlpRequest {
+	otlpSpans := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		span := otlpSpan{
+			TraceID:           s.SpanContext.TraceID.String(),
+			SpanID:            s.SpanContext.SpanID.String(),
+			Name:              s.Name,
+			Kind:              int(s.Kind),
+			StartTimeUnixNano: strconv.FormatInt(s.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.EndTime.UnixNano(), 10),
+			Attributes:        newOTLPAttributes(s.Attributes),
+			Status:            otlpStatus{Code: int(s.Status.Code), Message: s.Status.Message},
+		}
+		if s.ParentSpanID.IsValid() {
+			span.ParentSpanID = s.ParentSpanID.String()
+		}
+		for _, evt := range s.Events {
+			span.Events = append(span.Events, otlpEvent{
+				Name:         evt.Name,
+				TimeUnixNano: strconv.FormatInt(evt.Time.UnixNano(), 10),
+				Attributes:   newOTLPAttributes(evt.Attributes),
+			})
+		}
+		otlpSpans = append(otlpSpans, span)
+	}
+
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{
+			{
+				Resource: otlpResource{
+					Attributes: newOTLPAttributes([]Attribute{Attr("service.name", e.config.ServiceName)}),
+				},
+				ScopeSpans: []otlpScopeSpans{
+					{
+						Scope: otlpScope{Name: instrumentationScope},
+						Spans: otlpSpans,
+					},
+				},
+			},
+		},
+	}
+}
+
+// otlp* types are the JSON encoding of an OTLP trace export request.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+
+	otlpSpan struct {
+		TraceID           string          `json:"traceId"`
+		SpanID            string          `json:"spanId"`
+		ParentSpanID      string          `json:"parentSpanId,omitempty"`
+		Name              string          `json:"name"`
+		Kind              int             `json:"kind"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+		Events            []otlpEvent     `json:"events,omitempty"`
+		Status            otlpStatus      `json:"status"`
+	}
+
+	otlpEvent struct {
+		Name         string          `json:"name"`
+		TimeUnixNano string          `json:"timeUnixNano"`
+		Attributes   []otlpAttribute `json:"attributes,omitempty"`
+	}
+
+	otlpStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+
+	otlpAttribute struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+
+	otlpValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+func newOTLPAttributes(attrs []Attribute) []otlpAttribute {
+	otlpAttrs := make([]otlpAttribute, 0, len(attrs))
+	for _, attr := range attrs {
+		otlpAttrs = append(otlpAttrs, otlpAttribute{Key: attr.Key, Value: newOTLPValue(attr.Value)})
+	}
+	return otlpAttrs
+}
+
+func newOTLPValue(v any) otlpValue {
+	switch v := v.(type) {
+	case bool:
+		return otlpValue{BoolValue: &v}
+	case int:
+		s := strconv.FormatInt(int64(v), 10)
+		return otlpValue{IntValue: &s}
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		return otlpValue{IntValue: &s}
+	case float64:
+		return otlpValue{DoubleValue: &v}
+	case string:
+		return otlpValue{StringValue: &v}
+	default:
+		s := fmt.Sprint(v)
+		return otlpValue{StringValue: &s}
+	}
+}
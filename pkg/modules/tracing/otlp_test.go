@@ -0,0 +1,131 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// logBuffer is a log output safe for concurrent use.
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+// newCollector returns a collector responding with the status, and sending the names of the spans
+// of each export request on the returned channel.
+func newCollector(t *testing.T, status int) (*httptest.Server, chan []string) {
+	t.Helper()
+
+	requests := make(chan []string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req otlpRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		var names []string
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, s := range ss.Spans {
+					names = append(names, s.Name)
+				}
+			}
+		}
+		requests <- names
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, requests
+}
+
+func TestOTLPExporterQueue(t *testing.T) {
+	var (
+		logs     = &logBuffer{}
+		srv, req = newCollector(t, http.StatusOK)
+		e        = NewOTLPExporter(OTLPConfig{
+			Endpoint:     srv.URL,
+			BatchSize:    10,
+			BatchTimeout: time.Hour,
+			MaxQueueSize: 10,
+			Logger:       slog.New(slog.NewTextHandler(logs, nil)),
+		})
+	)
+
+	// the queue holds up to 10 spans, so the oldest two are dropped
+	var spans []SpanData
+	for _, name := range strings.Split("a b c d e f g h i j k l", " ") {
+		spans = append(spans, SpanData{Name: name})
+	}
+	err := e.ExportSpans(context.Background(), spans)
+	if err != nil {
+		t.Fatalf("ExportSpans() error = %v", err)
+	}
+
+	select {
+	case got := <-req:
+		if strings.Join(got, " ") != "c d e f g h i j k l" {
+			t.Errorf("exported spans = %v, want the 10 newest spans", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the full batch was not exported")
+	}
+
+	err = e.Shutdown(context.Background())
+	if err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if !strings.Contains(logs.String(), "spans=2") {
+		t.Errorf("logs = %q, want the dropped spans logged", logs.String())
+	}
+}
+
+func TestOTLPExporterFailure(t *testing.T) {
+	var (
+		logs     = &logBuffer{}
+		srv, req = newCollector(t, http.StatusServiceUnavailable)
+		e        = NewOTLPExporter(OTLPConfig{
+			Endpoint:     srv.URL,
+			BatchSize:    1,
+			BatchTimeout: time.Hour,
+			Logger:       slog.New(slog.NewTextHandler(logs, nil)),
+		})
+	)
+	defer e.Shutdown(context.Background())
+
+	err := e.ExportSpans(context.Background(), []SpanData{{Name: "a"}})
+	if err != nil {
+		t.Fatalf("ExportSpans() error = %v", err)
+	}
+	<-req
+
+	for deadline := time.Now().Add(5 * time.Second); !strings.Contains(logs.String(), "could not export spans"); {
+		if time.Now().After(deadline) {
+			t.Fatalf("logs = %q, want the failed export logged", logs.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !strings.Contains(logs.String(), "503 Service Unavailable") {
+		t.Errorf("logs = %q, want the collector's response logged", logs.String())
+	}
+}
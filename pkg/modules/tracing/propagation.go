@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// traceparentHeader is the W3C trace context header carrying the parent span.
+const traceparentHeader = "traceparent"
+
+// traceparentVersion is the supported version of the traceparent header.
+const traceparentVersion = "00"
+
+// flagSampled is the traceparent flag marking a sampled trace.
+const flagSampled = 0x01
+
+// Extract returns a copy of ctx carrying the span context propagated by the
+// W3C traceparent header, if h carries a valid one.
+func Extract(ctx context.Context, h http.Header) context.Context {
+	sc, ok := parseTraceparent(h.Get(traceparentHeader))
+	if !ok {
+		return ctx
+	}
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// Inject sets the W3C traceparent header of h to propagate the span context carried by ctx.
+func Inject(ctx context.Context, h http.Header) {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+
+	var flags byte
+	if sc.Sampled {
+		flags |= flagSampled
+	}
+
+	h.Set(traceparentHeader, fmt.Sprintf("%s-%s-%s-%02x", traceparentVersion, sc.TraceID, sc.SpanID, flags))
+}
+
+// Transport is an http.RoundTripper that propagates the span context of
+// a request's context to the server it is sent to.
+type Transport struct {
+	// Base is the transport requests are sent with. http.DefaultTransport is used if nil.
+	Base http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	r = r.Clone(r.Context())
+	Inject(r.Context(), r.Header)
+
+	return base.RoundTrip(r)
+}
+
+// parseTraceparent parses a traceparent header, e.g. "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+func parseTraceparent(v string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if (len(parts) < 4) || (len(parts[0]) != 2) || (parts[0] == "ff") {
+		return SpanContext{}, false
+	}
+	if (parts[0] == traceparentVersion) && (len(parts) != 4) {
+		return SpanContext{}, false
+	}
+
+	var (
+		sc    SpanContext
+		flags [1]byte
+	)
+
+	if (len(parts[1]) != 32) || (!decodeHex(sc.TraceID[:], parts[1])) {
+		return SpanContext{}, false
+	}
+	if (len(parts[2]) != 16) || (!decodeHex(sc.SpanID[:], parts[2])) {
+		return SpanContext{}, false
+	}
+	if (len(parts[3]) != 2) || (!decodeHex(flags[:], parts[3])) {
+		return SpanContext{}, false
+	}
+
+	sc.Sampled = flags[0]&flagSampled != 0
+	return sc, sc.IsValid()
+}
+
+// decodeHex decodes the lowercase hex string s into dst.
+func decodeHex(dst []byte, s string) bool {
+	if strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
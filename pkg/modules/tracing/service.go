@@ -0,0 +1,84 @@
+// Package tracing provides distributed tracing of requests and events, compatible
+// with the OpenTelemetry protocol and W3C trace context propagation.
+package tracing
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/huboh/vara"
+)
+
+// SpanOption configures a span started with [Service.Start].
+type SpanOption func(*SpanData)
+
+// WithKind sets the kind of the span.
+func WithKind(k SpanKind) SpanOption {
+	return func(d *SpanData) { d.Kind = k }
+}
+
+// WithAttributes sets attributes describing the span.
+func WithAttributes(attrs ...Attribute) SpanOption {
+	return func(d *SpanData) { d.Attributes = append(d.Attributes, attrs...) }
+}
+
+// Service starts spans and exports them once they end.
+type Service struct {
+	logger   *slog.Logger
+	exporter Exporter
+}
+
+func NewService(e Exporter, l *slog.Logger, lc *vara.Lifecycle) *Service {
+	s := &Service{
+		logger:   l,
+		exporter: e,
+	}
+
+	lc.Append(vara.LifecycleHook{
+		Name:   "tracing",
+		OnStop: e.Shutdown,
+	})
+
+	return s
+}
+
+// Start starts a span as a child of the span carried by ctx, or as the root of a new
+// trace if ctx carries none. The returned context carries the new span.
+func (s *Service) Start(ctx context.Context, name string, opts ...SpanOption) (context.Context, *Span) {
+	parent := SpanContextFromContext(ctx)
+
+	span := &Span{
+		service: s,
+		data: SpanData{
+			Name: name,
+			Kind: SpanKindInternal,
+			SpanContext: SpanContext{
+				TraceID: parent.TraceID,
+				SpanID:  newSpanID(),
+				Sampled: parent.Sampled,
+			},
+			ParentSpanID: parent.SpanID,
+		},
+	}
+
+	if !parent.IsValid() {
+		span.data.SpanContext.TraceID = newTraceID()
+		span.data.SpanContext.Sampled = true
+		span.data.ParentSpanID = SpanID{}
+	}
+
+	for _, opt := range opts {
+		opt(&span.data)
+	}
+	span.data.StartTime = time.Now()
+
+	return ContextWithSpan(ctx, span), span
+}
+
+func (s *Service) export(data SpanData) {
+	err := s.exporter.ExportSpans(context.Background(), []SpanData{data})
+	if err != nil {
+		s.logger.Warn("could not export span", "span", data.Name, "error", err)
+	}
+}
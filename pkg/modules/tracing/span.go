@@ -0,0 +1,149 @@
+package tracing
+
+import (
+	"sync"
+	"time"
+)
+
+// SpanKind describes the relationship of a span to its parent and children.
+type SpanKind int
+
+// recognized SpanKind, numbered as in the OpenTelemetry protocol
+const (
+	SpanKindInternal SpanKind = iota + 1
+	SpanKindServer
+	SpanKindClient
+	SpanKindProducer
+	SpanKindConsumer
+)
+
+// StatusCode is the status of the operation a span represents.
+type StatusCode int
+
+// recognized StatusCode, numbered as in the OpenTelemetry protocol
+const (
+	StatusUnset StatusCode = iota
+	StatusOK
+	StatusError
+)
+
+// Status is the status of a span.
+type Status struct {
+	Code    StatusCode
+	Message string
+}
+
+// Attribute is a key-value pair describing a span or an event.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Attr returns an attribute for the key and value.
+func Attr(key string, value any) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// SpanEvent is an event that happened during a span, e.g. an error.
+type SpanEvent struct {
+	Name       string
+	Time       time.Time
+	Attributes []Attribute
+}
+
+// SpanData is a snapshot of a span, as handed to exporters.
+type SpanData struct {
+	Name         string
+	Kind         SpanKind
+	SpanContext  SpanContext
+	ParentSpanID SpanID
+	StartTime    time.Time
+	EndTime      time.Time
+	Attributes   []Attribute
+	Events       []SpanEvent
+	Status       Status
+}
+
+// Span represents an operation within a trace. The methods of a nil span are no-ops.
+type Span struct {
+	mu      sync.Mutex
+	data    SpanData
+	ended   bool
+	service *Service
+}
+
+// SpanContext returns the span's context.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.data.SpanContext
+}
+
+// SetAttributes sets attributes describing the span.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.ended {
+		s.data.Attributes = append(s.data.Attributes, attrs...)
+	}
+}
+
+// SetStatus sets the span's status.
+func (s *Span) SetStatus(code StatusCode, msg string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.ended {
+		s.data.Status = Status{Code: code, Message: msg}
+	}
+}
+
+// RecordError records err as an event of the span and sets the span's status to [StatusError].
+func (s *Span) RecordError(err error) {
+	if (s == nil) || (err == nil) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.ended {
+		s.data.Status = Status{Code: StatusError, Message: err.Error()}
+		s.data.Events = append(s.data.Events, SpanEvent{
+			Name:       "exception",
+			Time:       time.Now(),
+			Attributes: []Attribute{Attr("exception.message", err.Error())},
+		})
+	}
+}
+
+// End ends the span and exports it. Calls after the first have no effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.EndTime = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if data.SpanContext.Sampled {
+		s.service.export(data)
+	}
+}
@@ -0,0 +1,106 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"math/rand/v2"
+)
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+// IsValid reports whether the trace id is not all zeroes.
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+func (t TraceID) MarshalText() ([]byte, error) { return []byte(t.String()), nil }
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// IsValid reports whether the span id is not all zeroes.
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+func (s SpanID) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+// SpanContext identifies a span and is propagated to the span's children, including
+// across process boundaries.
+type SpanContext struct {
+	// TraceID is the id of the trace the span belongs to
+	TraceID TraceID
+
+	// SpanID is the id of the span
+	SpanID SpanID
+
+	// Sampled specifies if the span is recorded and exported
+	Sampled bool
+
+	// Remote specifies if the span context was propagated from another process
+	Remote bool
+}
+
+// IsValid reports whether the span context has a valid trace and span id.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// spanKey is the context key of the active span.
+type spanKey struct{}
+
+// remoteSpanContextKey is the context key of a span context propagated from another process.
+type remoteSpanContextKey struct{}
+
+// ContextWithSpan returns a copy of ctx with span as the active span.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// ContextWithRemoteSpanContext returns a copy of ctx carrying a span context propagated
+// from another process, which becomes the parent of the spans started with ctx.
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	sc.Remote = true
+	return context.WithValue(ctx, remoteSpanContextKey{}, sc)
+}
+
+// SpanFromContext returns the active span of ctx, or nil if ctx carries none.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// SpanContextFromContext returns the span context of the active span of ctx, or the
+// span context propagated from another process if ctx carries no active span.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext()
+	}
+
+	sc, _ := ctx.Value(remoteSpanContextKey{}).(SpanContext)
+	return sc
+}
+
+func newTraceID() TraceID {
+	var t TraceID
+	for !t.IsValid() {
+		putUint64(t[:8], rand.Uint64())
+		putUint64(t[8:], rand.Uint64())
+	}
+	return t
+}
+
+func newSpanID() SpanID {
+	var s SpanID
+	for !s.IsValid() {
+		putUint64(s[:], rand.Uint64())
+	}
+	return s
+}
+
+func putUint64(b []byte, v uint64) {
+	for i := range b {
+		b[i] = byte(v >> (8 * (len(b) - 1 - i)))
+	}
+}
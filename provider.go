@@ -1,5 +1,12 @@
 package vara
 
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/dig"
+)
+
 // Provider is a marker interface for types that can be provided as dependencies.
 type Provider interface{}
 
@@ -21,3 +28,181 @@ type Provider interface{}
 // Any dependencies needed by the constructor will be resolved and instantiated
 // by the module's DI scope.
 type ProviderConstructor constructor
+
+// In can be embedded in a struct to request named or grouped providers as
+// a constructor's parameters.
+//
+// Example:
+//
+//	type PaymentParams struct {
+//		vara.In
+//
+//		Stripe   PaymentGateway   `name:"stripe"`
+//		Gateways []PaymentGateway `group:"gateways"`
+//	}
+//
+//	func NewPaymentService(p PaymentParams) *PaymentService {
+//		...
+//	}
+type In = dig.In
+
+// ProvideOption modifies how a provider constructor is registered in a module's DI scope.
+type ProvideOption func(*providerSpec)
+
+// Name registers the values produced by a provider constructor under the given name.
+// Consumers request it using a `name:"..."` tag on a field of an [In] struct.
+//
+// A provider may not be both named and grouped.
+func Name(name string) ProvideOption {
+	return func(p *providerSpec) {
+		p.name = name
+	}
+}
+
+// Group adds the values produced by a provider constructor to the named group.
+// Consumers request every value in the group as a slice using a `group:"..."`
+// tag on a field of an [In] struct.
+//
+// A provider may not be both named and grouped.
+func Group(group string) ProvideOption {
+	return func(p *providerSpec) {
+		p.group = group
+	}
+}
+
+// As registers the values produced by a provider constructor as the given interfaces
+// instead of its concrete type. Each value must be a pointer to an interface
+// that the provider implements, e.g. `new(PaymentGateway)`.
+func As(ifaces ...any) ProvideOption {
+	return func(p *providerSpec) {
+		p.as = append(p.as, ifaces...)
+	}
+}
+
+// Global registers the values produced by a provider constructor in the application's root
+// scope, making them available to every module without an import. This allows modules to
+// discover values that are provided anywhere in the application, e.g. members of a [Group].
+func Global() ProvideOption {
+	return func(p *providerSpec) {
+		p.global = true
+	}
+}
+
+// Provide annotates a provider constructor with options that control how its values
+// are registered, such as [Name], [Group] and [As].
+//
+// The returned value can be used anywhere a [ProviderConstructor] is accepted, e.g.
+// ProviderConstructors and ExportConstructors in [ModuleConfig]. To export an
+// annotated provider, list it with the same options in both places.
+//
+// Example:
+//
+//	ProviderConstructors: []vara.ProviderConstructor{
+//		vara.Provide(newStripeGateway, vara.Name("stripe"), vara.As(new(PaymentGateway))),
+//		vara.Provide(newPaypalGateway, vara.Group("gateways"), vara.As(new(PaymentGateway))),
+//	}
+func Provide(ctor ProviderConstructor, opts ...ProvideOption) ProviderConstructor {
+	spec := &providerSpec{ctor: ctor}
+
+	// allow annotating an already annotated constructor
+	if s, ok := ctor.(*providerSpec); ok {
+		spec.ctor = s.ctor
+		spec.name = s.name
+		spec.group = s.group
+		spec.global = s.global
+		spec.as = append(spec.as, s.as...)
+	}
+
+	for _, opt := range opts {
+		opt(spec)
+	}
+
+	return spec
+}
+
+// providerSpec is a provider constructor annotated with provide options.
+type providerSpec struct {
+	ctor   ProviderConstructor
+	name   string
+	group  string
+	global bool
+	as     []any
+}
+
+// token returns a token identifying the constructor along with its annotations,
+// so differently named or bound providers built by the same constructor are
+// told apart.
+func (p *providerSpec) token() string {
+	var b strings.Builder
+
+	b.WriteString(GetToken(p.ctor))
+	if p.name != "" {
+		fmt.Fprintf(&b, " name:%q", p.name)
+	}
+	if p.group != "" {
+		fmt.Fprintf(&b, " group:%q", p.group)
+	}
+	for _, iface := range p.as {
+		fmt.Fprintf(&b, " as:%T", iface)
+	}
+	if p.global {
+		b.WriteString(" global")
+	}
+
+	return b.String()
+}
+
+// options returns the dig provide options described by the spec.
+func (p *providerSpec) options() []dig.ProvideOption {
+	var opts []dig.ProvideOption
+
+	if p.name != "" {
+		opts = append(opts, dig.Name(p.name))
+	}
+	if p.group != "" {
+		opts = append(opts, dig.Group(p.group))
+	}
+	if len(p.as) > 0 {
+		opts = append(opts, dig.As(p.as...))
+	}
+	if p.global {
+		opts = append(opts, dig.Export(true))
+	}
+
+	return opts
+}
+
+// provide registers a provider constructor in the scope, applying
+// its annotations if it was built with [Provide].
+//
+// The annotations take precedence over opts.
+func provide(s scope, ctor ProviderConstructor, opts ...dig.ProvideOption) error {
+	if spec, ok := ctor.(*providerSpec); ok {
+		fn, lazyOpts := bindLazy(spec.ctor)
+		return s.Provide(fn, append(append(opts, lazyOpts...), spec.options()...)...)
+	}
+
+	fn, lazyOpts := bindLazy(ctor)
+	return s.Provide(fn, append(opts, lazyOpts...)...)
+}
+
+// Bind binds the interface T to the implementation built by ctor. The provider is
+// registered as T only, so consumers and importing modules depend on the interface
+// while the concrete type stays private to the module.
+//
+// Declaring the binding once and listing it in both ProviderConstructors and
+// ExportConstructors keeps swapping implementations, e.g. for tests, a one-line change.
+//
+// Example:
+//
+//	var userRepository = vara.Bind[UserRepository](newPgUserRepository)
+//
+//	func (m *Module) Config() *vara.ModuleConfig {
+//		return &vara.ModuleConfig{
+//			ExportConstructors:   []vara.ProviderConstructor{userRepository},
+//			ProviderConstructors: []vara.ProviderConstructor{userRepository},
+//		}
+//	}
+func Bind[T any](ctor ProviderConstructor, opts ...ProvideOption) ProviderConstructor {
+	return Provide(ctor, append(opts, As(new(T)))...)
+}
@@ -3,8 +3,6 @@ package vara
 import (
 	"fmt"
 	"net/http"
-
-	"go.uber.org/dig"
 )
 
 // RouteConfig defines the configuration for a route.
@@ -12,7 +10,7 @@ type RouteConfig struct {
 	Method   string       // The HTTP method (e.g., GET, POST) for the route.
 	Pattern  string       // The URL pattern that the route will match.
 	Handler  http.Handler // The HTTP handler to process requests on this route.
-	Metadata any          // Optional metadata that can be associated with the route.
+	Metadata Metadata     // Optional metadata that can be associated with the route.
 
 	Guards            []Guard            // Guards to enforce conditions before route handling.
 	GuardConstructors []GuardConstructor // Guard constructors for dynamic guard instantiation.
@@ -39,41 +37,14 @@ func newRoute(rCfg *RouteConfig, ctrl *controller) (*route, error) {
 	return r, nil
 }
 
-// _registerGuards registers all guards defined in the route configuration.
+// _registerGuards resolves the guards listed by the route's config. Like the controller's
+// guards, they are exactly those the route lists.
 func (r *route) _registerGuards() error {
-	var (
-		scp  = r.controller.module.scope
-		rCfg = r.RouteConfig
-		opts = []dig.ProvideOption{
-			dig.As(new(Guard)),
-			dig.Group(groupGuards.String()),
-		}
-	)
-
-	for _, grd := range rCfg.Guards {
-		err := scp.Provide(func() Guard { return grd }, opts...)
-		if err != nil {
-			return fmt.Errorf("error providing route guard (%T): %w", grd, err)
-		}
-	}
-
-	for _, grdCtor := range rCfg.GuardConstructors {
-		err := scp.Provide(grdCtor, opts...)
-		if err != nil {
-			return fmt.Errorf("error providing route guard (%T): %w", grdCtor, err)
-		}
+	guards, err := resolveGuards(r.controller.module.scope, r.Guards, r.GuardConstructors)
+	if err != nil {
+		return fmt.Errorf("error resolving route guards (%s): %w", r.controller.getPath(*r), err)
 	}
 
-	return scp.Invoke(
-		func(input guardGroupInput) error {
-			for _, grd := range input.Guards {
-				g, err := newGuard(grd)
-				if err != nil {
-					return err
-				}
-				r.guards = append(r.guards, g)
-			}
-			return nil
-		},
-	)
+	r.guards = guards
+	return nil
 }
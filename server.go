@@ -4,30 +4,94 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 )
 
+// defaultShutdownTimeout is how long a graceful shutdown may take by default.
+const defaultShutdownTimeout = 5 * time.Second
+
 type httpServer struct {
-	mux        *http.ServeMux
-	server     *http.Server
-	onShutdown func(context.Context) error
+	mux             *http.ServeMux
+	server          *http.Server
+	logger          *slog.Logger
+	onPreShutdown   func(context.Context) error
+	onShutdown      func(context.Context) error
+	shutdownTimeout time.Duration
+
+	// preflights answer the preflights of the routes with CORS, by path, in registration order.
+	preflights     map[string]*preflight
+	preflightKeys  []string
+	optionsHandled map[string]bool
 }
 
 func newHttpServer(mux *http.ServeMux) *httpServer {
 	return &httpServer{
-		mux: mux,
+		mux:             mux,
+		logger:          slog.Default(),
+		shutdownTimeout: defaultShutdownTimeout,
+		preflights:      make(map[string]*preflight),
+		optionsHandled:  make(map[string]bool),
 		server: &http.Server{
 			Handler: mux,
 		},
 	}
 }
 
+// handleRoute registers the route's handler for its pattern, and the route for preflights if
+// the CORS policy applies to it. Preflights are answered by the handlers registered with
+// registerPreflights, unless a route handles OPTIONS requests to the same path itself.
+func (s *httpServer) handleRoute(method, path string, h http.Handler, cors *corsPolicy) {
+	s.mux.Handle(strings.TrimSpace(method+" "+path), cors.handle(h))
+
+	key := getPreflightKey(path)
+	if method == http.MethodOptions {
+		s.optionsHandled[key] = true
+		return
+	}
+	if !cors.enabled() {
+		return
+	}
+
+	pf, exists := s.preflights[key]
+	if !exists {
+		pf = &preflight{pattern: (http.MethodOptions + " " + path), policy: cors}
+		s.preflights[key] = pf
+		s.preflightKeys = append(s.preflightKeys, key)
+	}
+	pf.addRoute(method, cors.handle(h))
+}
+
+// registerPreflights registers the handlers answering the preflights of the routes with CORS.
+func (s *httpServer) registerPreflights() (err error) {
+	defer func() {
+		// the mux panics on patterns conflicting with the registered ones
+		if r := recover(); r != nil {
+			err = fmt.Errorf("could not register CORS preflight: %v", r)
+		}
+	}()
+
+	for _, key := range s.preflightKeys {
+		if !s.optionsHandled[key] {
+			pf := s.preflights[key]
+			s.mux.Handle(pf.pattern, pf)
+		}
+	}
+	return nil
+}
+
+// setLogger sets the logger the server logs its messages with.
+func (s *httpServer) setLogger(l *slog.Logger) {
+	s.logger = l
+	s.server.ErrorLog = slog.NewLogLogger(l.Handler(), slog.LevelError)
+}
+
 // Listen starts the HTTP server on the specified host and port and listens
 // for incoming requests.
 //
@@ -49,37 +113,60 @@ func (s *httpServer) Listen(host string, port string) error {
 		}
 	}()
 
-	log.Printf("listening on (%s)\n", s.server.Addr)
+	s.logger.Info("listening", "addr", s.server.Addr)
 
 	select {
 	case <-sigChan:
 		return s.Shutdown(context.Background())
 
 	case err := <-errChan:
+		if err == nil {
+			// the server was shut down
+			return nil
+		}
 		return fmt.Errorf("error listening on (%s) : %w", s.server.Addr, err)
 	}
 }
 
-// Shutdown gracefully shuts down the HTTP server.
+// Shutdown gracefully shuts down the HTTP server. The server keeps serving requests while the
+// pre-shutdown function runs, and the shutdown timeout only starts once it returned.
 func (s *httpServer) Shutdown(c context.Context) error {
-	fn := s.onShutdown
-	ctx, cancel := context.WithTimeout(c, (time.Second * 5))
+	if fn := s.onPreShutdown; fn != nil {
+		err := fn(c)
+		if err != nil {
+			s.logger.Error("pre-shutdown failed", "error", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c, s.shutdownTimeout)
 	defer cancel()
 
-	if fn != nil {
+	if fn := s.onShutdown; fn != nil {
 		err := fn(ctx)
 		if err != nil {
-			fmt.Println(err)
+			s.logger.Error("shutdown failed", "error", err)
 		}
 	}
 
 	return s.server.Shutdown(ctx)
 }
 
-// RegisterOnShutdown registers a function that will be called before shutting own.
-func (s *httpServer) RegisterOnShutdown(f func(context.Context) error) error {
-	if f != nil {
-		s.onShutdown = f
+// RegisterOnShutdown registers the functions that will be called before shutting down: pre
+// first, while requests are still served, then fn, within the shutdown timeout.
+func (s *httpServer) RegisterOnShutdown(pre, fn func(context.Context) error) error {
+	if pre != nil {
+		s.onPreShutdown = pre
+	}
+	if fn != nil {
+		s.onShutdown = fn
 	}
 	return nil
 }
+
+// setShutdownTimeout sets how long the shutdown may take once pre-shutdown returned,
+// keeping the default if d is zero.
+func (s *httpServer) setShutdownTimeout(d time.Duration) {
+	if d > 0 {
+		s.shutdownTimeout = d
+	}
+}
@@ -2,7 +2,16 @@ package vara
 
 import "fmt"
 
+// tokener is implemented by values that carry their own identity token.
+type tokener interface {
+	token() string
+}
+
 // GetToken generates a unique token for the given value based on its type.
+// Annotated values, such as those returned by [Provide], include their annotations in the token.
 func GetToken(v any) string {
+	if t, ok := v.(tokener); ok {
+		return t.token()
+	}
 	return fmt.Sprintf("%T", v)
 }
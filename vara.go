@@ -2,7 +2,9 @@ package vara
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"sync"
 
 	"go.uber.org/dig"
 )
@@ -10,41 +12,160 @@ import (
 // App represents the main application
 type App struct {
 	module     *module
+	guards     *globalGuards
+	injector   *injector
 	container  *dig.Container
 	lifecycle  *Lifecycle
 	httpServer *httpServer
 }
 
 // New initializes a new instance of App, configuring the root module and dependencies.
-func New(module Module) (*App, error) {
-	var err error
+func New(module Module, opts ...Option) (*App, error) {
+	return NewWithContext(context.Background(), module, opts...)
+}
+
+// NewWithContext is like [New] but builds the application with the given context.
+//
+// Building the application is aborted once the context is done. Constructors that accept
+// a context.Context are injected a context carrying its values and, while the application is
+// built, its deadline. It is cancelled if building is aborted, so they can give up connecting
+// to external systems. Since constructors may also be called once the application is built,
+// e.g. for [Lazy] dependencies, it is not cancelled otherwise.
+//
+// NewWithContext returns as soon as building is aborted, without waiting for the constructors
+// that are running: they are left to return in the background, and so leak until they do.
+// No other provider constructor is called once building is aborted.
+func NewWithContext(ctx context.Context, root Module, opts ...Option) (*App, error) {
+	var (
+		err error
+		cfg = newOptions(opts...)
+	)
+
+	if cfg.bootstrapTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.bootstrapTimeout)
+		defer cancel()
+	}
+
+	ctorCtx, abort := newBuildContext(ctx)
+	defer func() {
+		if err != nil {
+			abort(err)
+		}
+	}()
 
 	c := dig.New()
+	mu := &sync.Mutex{}
+	inj := newInjector(mu, c)
 	lc := newLifecycle()
+	lc.setParallelism(cfg.lifecycleParallelism)
 	svr := newHttpServer(http.NewServeMux())
+	svr.setShutdownTimeout(cfg.shutdownTimeout)
+	gg := newGlobalGuards()
+	gc := &globalCORS{}
+
+	err = c.Provide(func() *injector { return inj })
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.Provide(func() context.Context { return ctorCtx })
+	if err != nil {
+		return nil, err
+	}
 
 	err = c.Provide(func() *Lifecycle { return lc })
 	if err != nil {
 		return nil, err
 	}
 
+	err = c.Provide(func() *globalGuards { return gg })
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.Provide(func() *globalCORS { return gc })
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.Provide(newReflector)
+	if err != nil {
+		return nil, err
+	}
+
 	err = c.Provide(func() *httpServer { return svr })
 	if err != nil {
 		return nil, err
 	}
 
-	m, err := newModule(module, c.Scope(GetToken(module)))
+	b := newBootstrap(ctorCtx, mu)
+
+	m, err := build(ctx, abort, func() (*module, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		m, err := newModule(root, c.Scope(GetToken(root)), nil, b)
+		if err != nil {
+			return nil, err
+		}
+
+		b.link()
+
+		if cfg.concurrentBuild {
+			err = b.run()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = c.Invoke(func(in globalGuardGroupInput) error {
+			guards, err := resolveGuards(c, append(cfg.globalGuards, in.Guards...), nil)
+			if err != nil {
+				return err
+			}
+			return gg.add(guards...)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		gc.policy, err = newCORSPolicy(c, cfg.cors)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving CORS config: %w", err)
+		}
+
+		err = m._registerAllControllers()
+		if err != nil {
+			return nil, err
+		}
+
+		err = svr.registerPreflights()
+		if err != nil {
+			return nil, err
+		}
+
+		err = c.Invoke(func(in loggerInput) { svr.setLogger(in.get()) })
+		if err != nil {
+			return nil, err
+		}
+
+		return m, nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	ctorCtx.built.Store(true)
 
 	a := &App{
 		module:     m,
+		guards:     gg,
+		injector:   inj,
 		container:  c,
 		lifecycle:  lc,
 		httpServer: svr,
 	}
-	err = a.httpServer.RegisterOnShutdown(a.onStop)
+	err = a.httpServer.RegisterOnShutdown(a.onPreStop, a.onStop)
 	if err != nil {
 		return nil, err
 	}
@@ -52,6 +173,46 @@ func New(module Module) (*App, error) {
 	return a, nil
 }
 
+// build calls fn to build the root module, and returns its results. Once ctx is done, building
+// is aborted with abort, and build returns without waiting for fn, which returns in the
+// background once the running constructors do.
+func build(ctx context.Context, abort context.CancelCauseFunc, fn func() (*module, error)) (*module, error) {
+	type result struct {
+		m   *module
+		err error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		m, err := fn()
+		done <- result{m, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.m, r.err
+
+	case <-ctx.Done():
+		abort(ctx.Err())
+		return nil, fmt.Errorf("bootstrap aborted: %w", ctx.Err())
+	}
+}
+
+// UseGlobalGuards applies the guards to every route of the application, after the
+// guards applied by [WithGlobalGuards] and [GlobalGuard]. It must be called before [App.Listen].
+// It fails if a guard implementing [RouteChecker] rejects any of the routes.
+func (a *App) UseGlobalGuards(guards ...Guard) error {
+	a.injector.mu.Lock()
+	defer a.injector.mu.Unlock()
+
+	resolved, err := resolveGuards(a.container, guards, nil)
+	if err != nil {
+		return err
+	}
+
+	return a.guards.add(resolved...)
+}
+
 func (a *App) Listen(host, port string) error {
 	err := a.onStart()
 	if err != nil {
@@ -64,6 +225,16 @@ func (a *App) Shutdown(ctx context.Context) error {
 	return a.httpServer.Shutdown(ctx)
 }
 
+// onPreStop pre-stops the lifecycle hooks. Hooks are stopped even if pre-stopping them
+// fails, so the resources they hold are released.
+func (a *App) onPreStop(ctx context.Context) (err error) {
+	err = a.lifecycle.preStop(ctx)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func (a *App) onStop(ctx context.Context) (err error) {
 	err = a.lifecycle.stop(ctx)
 	if err != nil {